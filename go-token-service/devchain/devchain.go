@@ -0,0 +1,226 @@
+package devchain
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"ERC20Whitelistable/go-token-service/contracts"
+)
+
+const (
+	gasLimit = uint64(8000000) // block gas limit
+)
+
+var (
+	// Funds every generated key gets at genesis - 1000 ETH
+	Funds = new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))
+)
+
+// Chain in-process simulated chain with ERC20Whitelistable deployed on it.
+// Every accepted transaction is mined right away, so it behaves like an instant-seal node.
+type Chain struct {
+	*backends.SimulatedBackend
+
+	Key             *ecdsa.PrivateKey // deployer - holds all of the contract's roles
+	ContractAddress common.Address    // deployed ERC20Whitelistable
+	Token           *token.Token      // contract instance
+
+	signer types.Signer
+	queued map[common.Address]map[uint64]*types.Transaction // future nonces waiting for the gap to fill
+
+	sync.Mutex // serializes sends and commits
+}
+
+// New starts a simulated chain, funds a generated key and deploys the contract with it
+func New() (*Chain, error) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWithKey(key)
+}
+
+// NewWithKey starts a simulated chain, funds the key and extra accounts and deploys the contract with the key
+func NewWithKey(key *ecdsa.PrivateKey, extra ...common.Address) (*Chain, error) {
+	deployer := crypto.PubkeyToAddress(key.PublicKey)
+
+	alloc := core.GenesisAlloc{deployer: {Balance: Funds}}
+	for _, addr := range extra {
+		alloc[addr] = core.GenesisAccount{Balance: Funds}
+	}
+	sim := backends.NewSimulatedBackend(alloc, gasLimit)
+
+	chain := &Chain{
+		SimulatedBackend: sim,
+		Key:              key,
+		signer:           types.LatestSigner(sim.Blockchain().Config()),
+		queued:           make(map[common.Address]map[uint64]*types.Transaction),
+	}
+
+	opts, err := bind.NewKeyedTransactorWithChainID(key, sim.Blockchain().Config().ChainID)
+	if err != nil {
+		return nil, err
+	}
+
+	address, _, instance, err := token.DeployToken(opts, sim)
+	if err != nil {
+		return nil, err
+	}
+	sim.Commit()
+
+	chain.ContractAddress = address
+	chain.Token = instance
+	return chain, nil
+}
+
+// SendTransaction mines the transaction right away. Transactions with a nonce ahead
+// of the account are held back - like a node's tx pool - until the missing ones arrive.
+func (c *Chain) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	c.Lock()
+	defer c.Unlock()
+
+	from, err := types.Sender(c.signer, tx)
+	if err != nil {
+		return err
+	}
+
+	nonce, err := c.SimulatedBackend.PendingNonceAt(ctx, from)
+	if err != nil {
+		return err
+	}
+	if c.queued[from][tx.Nonce()] != nil {
+		return fmt.Errorf("already known")
+	}
+
+	// simulated backend panics on transactions it can't include, answer like a node does
+	if baseFee := c.nextBaseFee(); baseFee != nil && tx.GasFeeCap().Cmp(baseFee) < 0 {
+		return fmt.Errorf("max fee per gas less than block base fee: address %s, maxFeePerGas: %s baseFee: %s", from.Hex(), tx.GasFeeCap(), baseFee)
+	}
+	if tx.Nonce() < nonce {
+		return fmt.Errorf("nonce too low: address %s, tx: %d state: %d", from.Hex(), tx.Nonce(), nonce)
+	}
+	if tx.Nonce() > nonce {
+		if c.queued[from] == nil {
+			c.queued[from] = make(map[uint64]*types.Transaction)
+		}
+		c.queued[from][tx.Nonce()] = tx
+		return nil
+	}
+
+	if err := c.SimulatedBackend.SendTransaction(ctx, tx); err != nil {
+		return err
+	}
+	c.Commit()
+
+	// release held back transactions which are next in line now
+	for next := tx.Nonce() + 1; c.queued[from][next] != nil; next++ {
+		queued := c.queued[from][next]
+		delete(c.queued[from], next)
+
+		if err := c.SimulatedBackend.SendTransaction(ctx, queued); err != nil {
+			break
+		}
+		c.Commit()
+	}
+
+	return nil
+}
+
+// PendingNonceAt counts held back transactions too
+func (c *Chain) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	nonce, err := c.SimulatedBackend.PendingNonceAt(ctx, account)
+	if err != nil {
+		return 0, err
+	}
+
+	for c.queued[account][nonce] != nil {
+		nonce++
+	}
+
+	return nonce, nil
+}
+
+// ChainID of the simulated chain
+func (c *Chain) ChainID(ctx context.Context) (*big.Int, error) {
+	return c.Blockchain().Config().ChainID, nil
+}
+
+// FeeHistory answers like eth_feeHistory, except that reward percentiles count transactions instead of gas used
+func (c *Chain) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	last := c.Blockchain().CurrentBlock().NumberU64()
+	if lastBlock != nil && lastBlock.Uint64() < last {
+		last = lastBlock.Uint64()
+	}
+	if blockCount > last+1 {
+		blockCount = last + 1
+	}
+
+	history := &ethereum.FeeHistory{OldestBlock: new(big.Int).SetUint64(last + 1 - blockCount)}
+	for number := last + 1 - blockCount; number <= last; number++ {
+		block := c.Blockchain().GetBlockByNumber(number)
+
+		tips := []*big.Int{}
+		for _, tx := range block.Transactions() {
+			tips = append(tips, tx.EffectiveGasTipValue(block.BaseFee()))
+		}
+		sort.Slice(tips, func(i, j int) bool { return tips[i].Cmp(tips[j]) < 0 })
+
+		rewards := []*big.Int{}
+		for _, percentile := range rewardPercentiles {
+			reward := new(big.Int)
+			if len(tips) > 0 {
+				reward = tips[int(float64(len(tips)-1)*percentile/100)]
+			}
+			rewards = append(rewards, reward)
+		}
+
+		history.Reward = append(history.Reward, rewards)
+		history.BaseFee = append(history.BaseFee, baseFeeOf(block.Header()))
+		history.GasUsedRatio = append(history.GasUsedRatio, float64(block.GasUsed())/float64(block.GasLimit()))
+	}
+
+	// one past the range like a node does - base fee of the block after it
+	next := new(big.Int)
+	if header := c.Blockchain().GetHeaderByNumber(last); header.BaseFee != nil {
+		next = misc.CalcBaseFee(c.Blockchain().Config(), header)
+	}
+	history.BaseFee = append(history.BaseFee, next)
+
+	return history, nil
+}
+
+// nextBaseFee base fee of the block the next transaction lands in, nil before London
+func (c *Chain) nextBaseFee() *big.Int {
+	head := c.Blockchain().CurrentBlock().Header()
+	if head.BaseFee == nil {
+		return nil
+	}
+
+	return misc.CalcBaseFee(c.Blockchain().Config(), head)
+}
+
+// baseFeeOf header's base fee, 0 before London
+func baseFeeOf(header *types.Header) *big.Int {
+	if header.BaseFee == nil {
+		return new(big.Int)
+	}
+
+	return header.BaseFee
+}
@@ -1,24 +1,111 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"log"
 	"os"
+	"strings"
 
-	"ERC20Whitelistable/go-token-service/token"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"ERC20Whitelistable/go-token-service/devchain"
 	"ERC20Whitelistable/go-token-service/server"
+	"ERC20Whitelistable/go-token-service/token"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "deploy" {
+		deploy(os.Args[2:])
+		return
+	}
+
 	// adding configuration file flag
 	cfpathFlag := flag.String("cfpath", "", "Configuration file.")
+	devFlag := flag.Bool("dev", false, "Run against an in-process simulated chain, no node needed.")
 	flag.Parse()
 
-	if len(os.Args) != 2 {
+	if *cfpathFlag == "" && !*devFlag {
 		log.Fatal("Give valid configuration path!")
 	}
 
+	// config is optional in dev mode - only non-chain settings are used
 	token.SetConfigFilePath(*cfpathFlag)
 
-	server.Run()
+	var wlt *token.WhitelistableToken
+	var err error
+	if *devFlag {
+		wlt, err = devToken()
+	} else {
+		wlt, err = token.GetWhitelistableToken()
+	}
+	if err != nil {
+		log.Fatal("Can't setup token context: ", err)
+	}
+
+	server.Run(wlt)
+}
+
+// devToken deploys the contract on a simulated chain and sets up token context on it
+func devToken() (*token.WhitelistableToken, error) {
+	chain, err := devchain.New()
+	if err != nil {
+		return nil, err
+	}
+
+	log.Println("Dev mode: simulated chain started")
+	log.Println("Dev mode: signer ", crypto.PubkeyToAddress(chain.Key.PublicKey).Hex())
+	log.Println("Dev mode: signer private key ", hexutil.Encode(crypto.FromECDSA(chain.Key))[2:])
+	log.Println("Dev mode: contract ", chain.ContractAddress.Hex())
+
+	return token.NewConfiguredToken(chain, chain.Key, chain.ContractAddress)
+}
+
+// deploy deploys the contract with the configured signer and prints the result as JSON
+func deploy(args []string) {
+	flags := flag.NewFlagSet("deploy", flag.ExitOnError)
+	cfpathFlag := flags.String("cfpath", "", "Configuration file.")
+	writeFlag := flags.Bool("write", false, "Write deployed contract's address back into the configuration file.")
+	mintersFlag := flags.String("minters", "", "Comma separated addresses to grant MINTER_ROLE.")
+	whitelistFlag := flags.String("whitelist", "", "Comma separated addresses to grant WHITELISTED_ROLE.")
+	flags.Parse(args)
+
+	if *cfpathFlag == "" {
+		log.Fatal("Give valid configuration path!")
+	}
+	token.SetConfigFilePath(*cfpathFlag)
+
+	output, err := token.DeployConfigured(&token.DeployInput{
+		Minters:     splitAddresses(*mintersFlag),
+		Whitelisted: splitAddresses(*whitelistFlag),
+	})
+	if output != nil {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(output)
+
+		// contract is deployed even if some of the grants failed
+		if *writeFlag {
+			if err := token.WriteContractAddress(*cfpathFlag, output.ContractAddress); err != nil {
+				log.Fatal("Can't write contract address: ", err)
+			}
+			log.Println("Contract address written to ", *cfpathFlag)
+		}
+	}
+	if err != nil {
+		log.Fatal("Deployment failed: ", err)
+	}
+}
+
+// splitAddresses splits comma separated list, empty entries are dropped
+func splitAddresses(list string) []string {
+	addresses := []string{}
+	for _, addr := range strings.Split(list, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			addresses = append(addresses, addr)
+		}
+	}
+
+	return addresses
 }
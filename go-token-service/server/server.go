@@ -1,11 +1,18 @@
 package server
 
 import (
+	"crypto/subtle"
 	"log"
-	"sync"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
 
 	"ERC20Whitelistable/go-token-service/token"
 )
@@ -15,8 +22,16 @@ var (
 	wlt *token.WhitelistableToken // token context common for all handlers
 )
 
+const (
+	defaultMembersLimit = 100  // page size for /roles/{role}/members
+	maxMembersLimit     = 1000 // page size upper bound for /roles/{role}/members
+	defaultEventsLimit  = 100  // page size for /events
+	maxEventsLimit      = 1000 // page size upper bound for /events
+)
+
 const (
 	internalServerError = "Internal Server Error!"
+	methodNotAllowed    = "Method Not Allowed!"
 )
 
 func auth(fn http.HandlerFunc) http.HandlerFunc {
@@ -33,6 +48,100 @@ func auth(fn http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// readInput decodes request's JSON body into input, on failure writes the response and returns false
+func readInput(w http.ResponseWriter, r *http.Request, input interface{}) bool {
+	reqBody, err := ioutil.ReadAll(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(internalServerError))
+		return false
+	}
+
+	err = json.Unmarshal(reqBody, input)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(internalServerError))
+		return false
+	}
+
+	return true
+}
+
+// idempotencyKey takes id from the Idempotency-Key header when the body has none, different keys in both are refused
+func idempotencyKey(w http.ResponseWriter, r *http.Request, id *string) bool {
+	key := r.Header.Get("Idempotency-Key")
+	if key != "" && *id != "" && key != *id {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("Idempotency-Key header differs from id"))
+		return false
+	}
+	if key != "" {
+		*id = key
+	}
+
+	return true
+}
+
+// rolesAuth guards role management with its own credentials from the config
+func rolesAuth(fn http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		creds := token.GetConfig().RolesAuth
+
+		// no credentials configured - role management is disabled
+		if !ok || creds.User == "" ||
+			subtle.ConstantTimeCompare([]byte(user), []byte(creds.User)) != 1 ||
+			subtle.ConstantTimeCompare([]byte(pass), []byte(creds.Pass)) != 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte("401 Unauthorized!"))
+			return
+		}
+		fn(w, r)
+	}
+}
+
+// writeTxOutput encodes output of a single transaction, refused inputs get 4xx status
+func writeTxOutput(w http.ResponseWriter, output *token.TxOutput, err error) {
+	switch err {
+	case token.UnknownRoleError, token.InvalidAddressError, token.InvalidAmountError,
+		token.NegativeAmountError, token.TooManyDecimalsError, token.UnknownUnitsError:
+		w.WriteHeader(http.StatusBadRequest)
+	case token.LastAdminError, token.SelfAdminError:
+		w.WriteHeader(http.StatusConflict)
+	case token.NotWhitelistedError, token.InsufficientBalanceError, token.InsufficientAllowanceError, token.GasCapError,
+		token.IdempotencyKeyReusedError:
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	case token.GasPriceTooHighError:
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(output)
+}
+
+// writeMultiOutput encodes output of a batch, refused batches get 413 or 429 with Retry-After
+func writeMultiOutput(w http.ResponseWriter, multiOutput *token.TxMultiOutput, err error) {
+	if ok := writeBatchRefused(w, err, wlt.Batches.MaxSize); !ok {
+		json.NewEncoder(w).Encode(multiOutput)
+	}
+}
+
+// writeBatchRefused writes 413 or 429 with Retry-After for batches which were refused
+func writeBatchRefused(w http.ResponseWriter, err error, maxSize int) bool {
+	switch err {
+	case token.BatchTooLargeError:
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		w.Write([]byte(fmt.Sprintf("Batch Too Large - %d items at most", maxSize)))
+	case token.BatchBusyError:
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wlt.Batches.RetryAfter.Seconds()))))
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(err.Error()))
+	default:
+		return false
+	}
+
+	return true
+}
+
 func homePageHandler(w http.ResponseWriter, r *http.Request) {
 	log.Println("Endpoint: homePage")
 
@@ -43,150 +152,494 @@ func whitelistHandler(w http.ResponseWriter, r *http.Request) {
 	log.Println("Endpoint: whitelist")
 	var input token.WhitelistInput
 
-	reqBody, err := ioutil.ReadAll(r.Body)
-	defer r.Body.Close()
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte(internalServerError))
+	if ok := readInput(w, r, &input); !ok {
 		return
 	}
-
-	err = json.Unmarshal(reqBody, &input)
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte(internalServerError))
+	if ok := idempotencyKey(w, r, &input.ID); !ok {
 		return
 	}
 
-	output, _ := wlt.WhitelistAddress(&input)
-	json.NewEncoder(w).Encode(output)
+	output, err := wlt.WhitelistAddress(&input)
+	writeTxOutput(w, output, err)
 }
 
 func whitelistMultipleHandler(w http.ResponseWriter, r *http.Request) {
 	log.Println("Endpoint: whitelist multiple")
 	var input token.WhitelistMultiInput
 
-	reqBody, err := ioutil.ReadAll(r.Body)
-	defer r.Body.Close()
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte(internalServerError))
+	if ok := readInput(w, r, &input); !ok {
 		return
 	}
 
-	err = json.Unmarshal(reqBody, &input)
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte(internalServerError))
+	multiOutput, err := wlt.WhitelistMultiple(&input)
+	writeMultiOutput(w, multiOutput, err)
+}
+
+func whitelistRevokeHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Endpoint: whitelist revoke")
+	var input token.WhitelistInput
+
+	if ok := readInput(w, r, &input); !ok {
+		return
+	}
+	if ok := idempotencyKey(w, r, &input.ID); !ok {
 		return
 	}
 
-	multiOutput := token.GetTxMultiOutput()
-	var wg sync.WaitGroup
+	output, err := wlt.RevokeWhitelist(&input)
+	writeTxOutput(w, output, err)
+}
 
-	for _, addr := range input.Addresses {
-		// skip incorrect inputs
-		if addr.Address == "" {
-			continue
-		}
+func whitelistRevokeMultipleHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Endpoint: whitelist revoke multiple")
+	var input token.WhitelistMultiInput
 
-		wg.Add(1)
-		go func(t token.WhitelistInput, wg *sync.WaitGroup) {
-			defer wg.Done()
-			// no need to handle the error
-			output, _ := wlt.WhitelistAddress(&t)
-			multiOutput.Add(output)
-		}(addr, &wg)
+	if ok := readInput(w, r, &input); !ok {
+		return
 	}
-	wg.Wait()
 
-	json.NewEncoder(w).Encode(multiOutput)
+	multiOutput, err := wlt.RevokeWhitelistMultiple(&input)
+	writeMultiOutput(w, multiOutput, err)
 }
 
 func mintHandler(w http.ResponseWriter, r *http.Request) {
 	log.Println("Endpoint: mint")
 	var input token.MintInput
 
-	reqBody, err := ioutil.ReadAll(r.Body)
-	defer r.Body.Close()
+	if ok := readInput(w, r, &input); !ok {
+		return
+	}
+	if ok := idempotencyKey(w, r, &input.ID); !ok {
+		return
+	}
+
+	output, err := wlt.Mint(&input)
+	writeTxOutput(w, output, err)
+}
+
+func mintMultipleHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Endpoint: mint multiple")
+	var input token.MintMultiInput
+
+	if ok := readInput(w, r, &input); !ok {
+		return
+	}
+
+	multiOutput, err := wlt.MintMultiple(&input)
+	writeMultiOutput(w, multiOutput, err)
+}
+
+func transferHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Endpoint: transfer")
+	var input token.TransferInput
+
+	if ok := readInput(w, r, &input); !ok {
+		return
+	}
+
+	output, err := wlt.Transfer(&input)
+	writeTxOutput(w, output, err)
+}
+
+func transferMultipleHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Endpoint: transfer multiple")
+	var input token.TransferMultiInput
+
+	if ok := readInput(w, r, &input); !ok {
+		return
+	}
+
+	multiOutput, err := wlt.TransferMultiple(&input)
+	writeMultiOutput(w, multiOutput, err)
+}
+
+func transferFromHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Endpoint: transfer from")
+	var input token.TransferFromInput
+
+	if ok := readInput(w, r, &input); !ok {
+		return
+	}
+
+	output, err := wlt.TransferFrom(&input)
+	writeTxOutput(w, output, err)
+}
+
+// allowanceHandler serves GET /allowance/{owner}/{spender}
+func allowanceHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Endpoint: allowance")
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		w.Write([]byte(methodNotAllowed))
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/allowance/"), "/"), "/")
+	if len(parts) != 2 {
+		http.NotFound(w, r)
+		return
+	}
+
+	allowance, err := wlt.GetAllowance(parts[0], parts[1])
+	if err == token.InvalidAddressError {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
 	if err != nil {
+		log.Println("Allowance failed: ", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Write([]byte(internalServerError))
 		return
 	}
 
-	err = json.Unmarshal(reqBody, &input)
+	json.NewEncoder(w).Encode(&token.AllowanceOutput{
+		Owner:     common.HexToAddress(parts[0]).Hex(),
+		Spender:   common.HexToAddress(parts[1]).Hex(),
+		Allowance: allowance.String(),
+	})
+}
+
+// allowanceChangeHandler serves /allowance/approve, /allowance/increase and /allowance/decrease
+func allowanceChangeHandler(action func(*token.AllowanceInput) (*token.TxOutput, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Println("Endpoint: allowance change", r.URL.Path)
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			w.Write([]byte(methodNotAllowed))
+			return
+		}
+
+		var input token.AllowanceInput
+		if ok := readInput(w, r, &input); !ok {
+			return
+		}
+
+		output, err := action(&input)
+		writeTxOutput(w, output, err)
+	}
+}
+
+func addressHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Endpoint: address")
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		w.Write([]byte(methodNotAllowed))
+		return
+	}
+
+	address := strings.TrimPrefix(r.URL.Path, "/address/")
+	output, err := wlt.GetAddressInfo(address, r.URL.Query().Get("spender"))
+	if err == token.InvalidAddressError {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
 	if err != nil {
+		log.Println("Address info failed: ", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Write([]byte(internalServerError))
 		return
 	}
 
-	output, _ := wlt.Mint(&input)
 	json.NewEncoder(w).Encode(output)
 }
 
-func mintMultipleHandler(w http.ResponseWriter, r *http.Request) {
-	log.Println("Endpoint: mint multiple")
-	var input token.MintMultiInput
+func tokenInfoHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Endpoint: token")
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		w.Write([]byte(methodNotAllowed))
+		return
+	}
 
-	reqBody, err := ioutil.ReadAll(r.Body)
-	defer r.Body.Close()
+	output, err := wlt.GetTokenInfo()
 	if err != nil {
+		log.Println("Token info failed: ", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Write([]byte(internalServerError))
 		return
 	}
 
-	err = json.Unmarshal(reqBody, &input)
+	json.NewEncoder(w).Encode(output)
+}
+
+// roleMembersHandler serves /roles/{role}/members?offset=&limit=
+func roleMembersHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Endpoint: role members")
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		w.Write([]byte(methodNotAllowed))
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/roles/"), "/"), "/")
+	if len(parts) != 2 || parts[1] != "members" {
+		http.NotFound(w, r)
+		return
+	}
+
+	offset, limit := uint64(0), uint64(defaultMembersLimit)
+	var err error
+	query := r.URL.Query()
+	if v := query.Get("offset"); v != "" {
+		if offset, err = strconv.ParseUint(v, 10, 64); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("Invalid offset"))
+			return
+		}
+	}
+	if v := query.Get("limit"); v != "" {
+		if limit, err = strconv.ParseUint(v, 10, 64); err != nil || limit == 0 || limit > maxMembersLimit {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("Invalid limit"))
+			return
+		}
+	}
+
+	output, err := wlt.GetRoleMembers(parts[0], offset, limit)
+	if err == token.UnknownRoleError {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(err.Error()))
+		return
+	}
 	if err != nil {
+		log.Println("Role members failed: ", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Write([]byte(internalServerError))
 		return
 	}
 
-	multiOutput := token.GetTxMultiOutput()
-	var wg sync.WaitGroup
+	json.NewEncoder(w).Encode(output)
+}
 
-	for _, mint := range input.Mints {
-		// skip incorrect inputs
-		if mint.Address == "" {
-			continue
+// roleManagementHandler serves /roles/grant, /roles/revoke and /roles/renounce
+func roleManagementHandler(action func(*token.RoleInput) (*token.TxOutput, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Println("Endpoint: role management", r.URL.Path)
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			w.Write([]byte(methodNotAllowed))
+			return
+		}
+
+		var input token.RoleInput
+		if ok := readInput(w, r, &input); !ok {
+			return
 		}
 
-		wg.Add(1)
-		go func(t token.MintInput, wg *sync.WaitGroup) {
-			defer wg.Done()
-			// no need to handle the error
-			output, _ := wlt.Mint(&t)
-			multiOutput.Add(output)
-		}(mint, &wg)
+		output, err := action(&input)
+		writeTxOutput(w, output, err)
+	}
+}
+
+func nonceHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Endpoint: nonce")
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		w.Write([]byte(methodNotAllowed))
+		return
+	}
+
+	json.NewEncoder(w).Encode(wlt.Nonces.State())
+}
+
+// txHandler serves GET /tx/{id}, id is a job id or a transaction hash
+func txHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Endpoint: tx")
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		w.Write([]byte(methodNotAllowed))
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/tx/")
+	output, err := wlt.GetJob(id)
+	if err == ethereum.NotFound {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		log.Println("Job lookup failed: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(internalServerError))
+		return
+	}
+
+	json.NewEncoder(w).Encode(output)
+}
+
+// writeBatchJob encodes batch job's snapshot with status
+func writeBatchJob(w http.ResponseWriter, status int, job *token.BatchJob, err error) {
+	switch err {
+	case nil:
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(job)
+	case token.UnknownBatchJobError:
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(err.Error()))
+	case token.BatchJobFinishedError:
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(err.Error()))
+	default:
+		writeBatchRefused(w, err, wlt.Batches.MaxJobSize)
 	}
-	wg.Wait()
+}
+
+// mintJobHandler serves POST /jobs/mint, minting continues in the background
+func mintJobHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Endpoint: mint job")
+	var input token.MintMultiInput
+
+	if ok := readInput(w, r, &input); !ok {
+		return
+	}
+
+	job, err := wlt.StartMintJob(&input)
+	writeBatchJob(w, http.StatusAccepted, job, err)
+}
+
+// whitelistJobHandler serves POST /jobs/whitelist, whitelisting continues in the background
+func whitelistJobHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Endpoint: whitelist job")
+	var input token.WhitelistMultiInput
+
+	if ok := readInput(w, r, &input); !ok {
+		return
+	}
+
+	job, err := wlt.StartWhitelistJob(&input)
+	writeBatchJob(w, http.StatusAccepted, job, err)
+}
 
-	json.NewEncoder(w).Encode(multiOutput)
+// jobHandler serves GET /jobs/{id} with per item progress and POST /jobs/{id}/cancel
+func jobHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Endpoint: job")
+	id := strings.TrimPrefix(r.URL.Path, "/jobs/")
+
+	switch {
+	case r.Method == http.MethodGet && !strings.Contains(id, "/"):
+		job, err := wlt.GetBatchJob(id)
+		writeBatchJob(w, http.StatusOK, job, err)
+	case r.Method == http.MethodPost && strings.HasSuffix(id, "/cancel"):
+		job, err := wlt.CancelBatchJob(strings.TrimSuffix(id, "/cancel"))
+		writeBatchJob(w, http.StatusOK, job, err)
+	case r.Method == http.MethodGet || r.Method == http.MethodPost:
+		http.NotFound(w, r)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		w.Write([]byte(methodNotAllowed))
+	}
 }
 
+// eventsHandler serves GET /events?name=&address=&fromBlock=&toBlock=&limit= from the indexer
+func eventsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Endpoint: events")
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		w.Write([]byte(methodNotAllowed))
+		return
+	}
+	if wlt.Indexer == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("Indexer Disabled"))
+		return
+	}
 
-func Run() {
-	// initialize token context
+	query := r.URL.Query()
+	filter := &token.EventFilter{Name: query.Get("name"), Address: query.Get("address"), Limit: defaultEventsLimit}
 	var err error
-	wlt, err = token.GetWhitelistableToken()
+	if v := query.Get("fromBlock"); v != "" {
+		if filter.FromBlock, err = strconv.ParseUint(v, 10, 64); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("Invalid fromBlock"))
+			return
+		}
+	}
+	if v := query.Get("toBlock"); v != "" {
+		if filter.ToBlock, err = strconv.ParseUint(v, 10, 64); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("Invalid toBlock"))
+			return
+		}
+	}
+	if v := query.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil || filter.Limit <= 0 || filter.Limit > maxEventsLimit {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("Invalid limit"))
+			return
+		}
+	}
+	if v := query.Get("after"); v != "" {
+		if filter.After, err = token.ParseEventPosition(v); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("Invalid after"))
+			return
+		}
+	}
+
+	output := &token.EventsOutput{}
+	if output.Cursor, err = wlt.Indexer.Cursor(); err == nil {
+		output.Events, err = wlt.Indexer.Events(filter)
+	}
 	if err != nil {
-		log.Println("Can't setup token context: ", err)
+		log.Println("Events failed: ", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(internalServerError))
 		return
 	}
 
-	http.HandleFunc("/", auth(homePageHandler))
-	http.HandleFunc("/whitelist", auth(whitelistHandler))
-	http.HandleFunc("/whitelist/multiple", auth(whitelistMultipleHandler))
-	http.HandleFunc("/mint", auth(mintHandler))
-	http.HandleFunc("/mint/multiple", auth(mintMultipleHandler))
+	if len(output.Events) == filter.Limit {
+		last := output.Events[len(output.Events)-1]
+		output.Next = token.EventPosition{BlockNumber: last.BlockNumber, LogIndex: last.LogIndex}.String()
+	}
+
+	json.NewEncoder(w).Encode(output)
+}
+
+// NewHandler routes all endpoints on top of the given token context
+func NewHandler(t *token.WhitelistableToken) http.Handler {
+	wlt = t
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/", auth(homePageHandler))
+	mux.HandleFunc("/whitelist", auth(whitelistHandler))
+	mux.HandleFunc("/whitelist/multiple", auth(whitelistMultipleHandler))
+	mux.HandleFunc("/whitelist/revoke", auth(whitelistRevokeHandler))
+	mux.HandleFunc("/whitelist/revoke/multiple", auth(whitelistRevokeMultipleHandler))
+	mux.HandleFunc("/mint", auth(mintHandler))
+	mux.HandleFunc("/mint/multiple", auth(mintMultipleHandler))
+	mux.HandleFunc("/transfer", auth(transferHandler))
+	mux.HandleFunc("/transfer/multiple", auth(transferMultipleHandler))
+	mux.HandleFunc("/transfer/from", auth(transferFromHandler))
+	mux.HandleFunc("/allowance/", auth(allowanceHandler))
+	mux.HandleFunc("/allowance/approve", auth(allowanceChangeHandler(wlt.Approve)))
+	mux.HandleFunc("/allowance/increase", auth(allowanceChangeHandler(wlt.IncreaseAllowance)))
+	mux.HandleFunc("/allowance/decrease", auth(allowanceChangeHandler(wlt.DecreaseAllowance)))
+	mux.HandleFunc("/address/", auth(addressHandler))
+	mux.HandleFunc("/token", auth(tokenInfoHandler))
+	mux.HandleFunc("/nonce", auth(nonceHandler))
+	mux.HandleFunc("/tx/", auth(txHandler))
+	mux.HandleFunc("/jobs/mint", auth(mintJobHandler))
+	mux.HandleFunc("/jobs/whitelist", auth(whitelistJobHandler))
+	mux.HandleFunc("/jobs/", auth(jobHandler))
+	mux.HandleFunc("/events", auth(eventsHandler))
+	mux.HandleFunc("/roles/", auth(roleMembersHandler))
+	mux.HandleFunc("/roles/grant", rolesAuth(roleManagementHandler(wlt.GrantRole)))
+	mux.HandleFunc("/roles/revoke", rolesAuth(roleManagementHandler(wlt.RevokeRole)))
+	mux.HandleFunc("/roles/renounce", rolesAuth(roleManagementHandler(wlt.RenounceRole)))
+
+	return mux
+}
+
+// Run serves all endpoints on top of the given token context
+func Run(t *token.WhitelistableToken) {
+	handler := NewHandler(t)
 
 	log.Println("Server starting ...")
 	defer log.Println("Server shutting down ...")
 
-	err = http.ListenAndServe(":8080", nil)
+	err := http.ListenAndServe(":8080", handler)
 	if err != nil {
 		log.Println("Server failed with error: ", err)
 		return
@@ -0,0 +1,285 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"ERC20Whitelistable/go-token-service/server"
+	"ERC20Whitelistable/go-token-service/token"
+	"ERC20Whitelistable/go-token-service/tokentest"
+)
+
+// post sends authorized JSON request and decodes the response into output
+func post(t *testing.T, srv *httptest.Server, path, body string, output interface{}) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, srv.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.SetBasicAuth("admin", "pass")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if output != nil {
+		if err := json.NewDecoder(resp.Body).Decode(output); err != nil {
+			t.Fatalf("can't decode %s response: %v", path, err)
+		}
+	}
+
+	return resp
+}
+
+func newServer(t *testing.T) (*tokentest.Env, *httptest.Server) {
+	env := tokentest.New(t)
+	srv := httptest.NewServer(server.NewHandler(env.Token))
+	t.Cleanup(srv.Close)
+
+	return env, srv
+}
+
+func TestUnauthorized(t *testing.T) {
+	_, srv := newServer(t)
+
+	resp, err := http.Post(srv.URL+"/whitelist", "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", resp.StatusCode)
+	}
+}
+
+func TestWhitelistRevokeHandler(t *testing.T) {
+	_, srv := newServer(t)
+
+	var output token.TxOutput
+	if resp := post(t, srv, "/whitelist/revoke", `{"address": "not an address"}`, &output); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", resp.StatusCode)
+	}
+	if output.Error == nil || output.Error.Code != token.CodeInvalidAddress {
+		t.Errorf("expected %s, got %+v", token.CodeInvalidAddress, output.Error)
+	}
+}
+
+func TestWhitelistMultipleHandler(t *testing.T) {
+	env, srv := newServer(t)
+	first, second := tokentest.NewAddress(t), tokentest.NewAddress(t)
+
+	var output token.TxMultiOutput
+	body := `{"addresses": [{"address": "` + first + `"}, {"address": ""}, {"address": "` + second + `"}]}`
+	post(t, srv, "/whitelist/multiple", body, &output)
+
+	// in input order, empty address is reported as skipped
+	if len(output.Transactions) != 3 {
+		t.Fatalf("expected 3 transactions, got %+v", output.Transactions)
+	}
+	for index, tx := range output.Transactions {
+		if tx.Index != index {
+			t.Errorf("expected index %d, got %+v", index, tx)
+		}
+	}
+	if tx := output.Transactions[0]; tx.Address != first || tx.State != token.JobBroadcast {
+		t.Errorf("unexpected output: %+v", tx)
+	}
+	if tx := output.Transactions[1]; tx.State != token.ItemSkipped || tx.Error == nil || tx.Error.Code != token.CodeEmptyAddress {
+		t.Errorf("unexpected output: %+v", tx)
+	}
+	if tx := output.Transactions[2]; tx.Address != second || tx.State != token.JobBroadcast {
+		t.Errorf("unexpected output: %+v", tx)
+	}
+	for _, address := range []string{first, second} {
+		if !env.HasRole(t, env.Token.WhitelistedRole, address) {
+			t.Errorf("%s is not whitelisted", address)
+		}
+	}
+}
+
+func TestMintMultipleHandler(t *testing.T) {
+	env, srv := newServer(t)
+	whitelisted, other := tokentest.NewAddress(t), tokentest.NewAddress(t)
+	env.Whitelist(t, whitelisted)
+
+	var output token.TxMultiOutput
+	body := `{"mints": [{"address": "` + whitelisted + `", "amount": "50"}, {"address": "` + other + `", "amount": "50"}, {"address": "0x1234", "amount": "50"}]}`
+	post(t, srv, "/mint/multiple", body, &output)
+
+	if len(output.Transactions) != 3 {
+		t.Fatalf("expected 3 transactions, got %+v", output.Transactions)
+	}
+	if tx := output.Transactions[0]; tx.Address != whitelisted || tx.State != token.JobBroadcast || tx.Error != nil {
+		t.Errorf("unexpected output: %+v", tx)
+	}
+	// minting to non whitelisted address is refused before estimateGas
+	if tx := output.Transactions[1]; tx.Address != other || tx.State != token.JobFailed || tx.Error.Code != token.CodeNotWhitelisted {
+		t.Errorf("unexpected output: %+v", tx)
+	}
+	if tx := output.Transactions[2]; tx.Index != 2 || tx.Error.Code != token.CodeInvalidAddress {
+		t.Errorf("unexpected output: %+v", tx)
+	}
+	if balance := env.BalanceOf(t, whitelisted); balance.String() != "50" {
+		t.Errorf("expected balance 50, got %s", balance)
+	}
+	if balance := env.BalanceOf(t, other); balance.Sign() != 0 {
+		t.Errorf("expected balance 0, got %s", balance)
+	}
+}
+
+func TestMultipleBackpressure(t *testing.T) {
+	env, srv := newServer(t)
+	body := `{"addresses": [{"address": "` + tokentest.NewAddress(t) + `"}, {"address": "` + tokentest.NewAddress(t) + `"}]}`
+
+	env.Token.Batches = token.NewBatchLimits(2, 1, 1, 1, time.Second)
+	if resp := post(t, srv, "/whitelist/multiple", body, nil); resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413, got %d", resp.StatusCode)
+	}
+
+	// no free slot - every batch is refused
+	env.Token.Batches = token.NewBatchLimits(2, 10, 10, 0, 1500*time.Millisecond)
+	resp := post(t, srv, "/whitelist/multiple", body, nil)
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") != "2" {
+		t.Errorf("expected 429 with Retry-After 2, got %d %q", resp.StatusCode, resp.Header.Get("Retry-After"))
+	}
+}
+
+func TestJobsHandlers(t *testing.T) {
+	env, srv := newServer(t)
+	whitelisted := tokentest.NewAddress(t)
+	env.Whitelist(t, whitelisted)
+
+	var job token.BatchJob
+	body := `{"mints": [{"address": "` + whitelisted + `", "amount": "50"}]}`
+	if resp := post(t, srv, "/jobs/mint", body, &job); resp.StatusCode != http.StatusAccepted || job.ID == "" {
+		t.Fatalf("expected 202 with job id, got %d %+v", resp.StatusCode, job)
+	}
+
+	for deadline := time.Now().Add(10 * time.Second); job.Finished == nil && time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+"/jobs/"+job.ID, nil)
+		req.SetBasicAuth("admin", "pass")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		json.NewDecoder(resp.Body).Decode(&job)
+		resp.Body.Close()
+	}
+	if job.State != token.BatchDone || job.Progress.Total != 1 || job.Transactions[0].JobID == "" {
+		t.Errorf("unexpected job: %+v", job)
+	}
+
+	if resp := post(t, srv, "/jobs/"+job.ID+"/cancel", "", nil); resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected 409, got %d", resp.StatusCode)
+	}
+	if resp := post(t, srv, "/jobs/unknown/cancel", "", nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestIdempotencyKeyHeader(t *testing.T) {
+	_, srv := newServer(t)
+	address := tokentest.NewAddress(t)
+
+	postKeyed := func(key, body string, output interface{}) *http.Response {
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/whitelist", strings.NewReader(body))
+		req.SetBasicAuth("admin", "pass")
+		req.Header.Set("Idempotency-Key", key)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if output != nil {
+			json.NewDecoder(resp.Body).Decode(output)
+		}
+		return resp
+	}
+
+	var first, again token.TxOutput
+	postKeyed("whitelist-1", `{"address": "`+address+`"}`, &first)
+	postKeyed("whitelist-1", `{"address": "`+address+`"}`, &again)
+	if first.JobID == "" || again.JobID != first.JobID {
+		t.Errorf("expected original output %+v, got %+v", first, again)
+	}
+
+	if resp := postKeyed("whitelist-1", `{"address": "`+tokentest.NewAddress(t)+`"}`, nil); resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422, got %d", resp.StatusCode)
+	}
+	if resp := postKeyed("whitelist-1", `{"address": "`+address+`", "id": "other"}`, nil); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestEventsHandler(t *testing.T) {
+	env, srv := newServer(t)
+	address := tokentest.NewAddress(t)
+	env.Whitelist(t, address)
+
+	get := func(path string, output interface{}) *http.Response {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		req.SetBasicAuth("admin", "pass")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if output != nil {
+			json.NewDecoder(resp.Body).Decode(output)
+		}
+		return resp
+	}
+
+	if resp := get("/events", nil); resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 without indexer, got %d", resp.StatusCode)
+	}
+
+	idx, err := token.NewIndexer(env.Chain, env.Chain.ContractAddress, env.Token.Roles(), token.NewMemoryEventStore(), 0, 100, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := idx.Step(); err != nil {
+		t.Fatal(err)
+	}
+	env.Token.Indexer = idx
+
+	var output token.EventsOutput
+	get("/events?name=RoleGranted&address="+address, &output)
+	if output.Cursor == nil || len(output.Events) != 1 || output.Events[0].Account != address {
+		t.Errorf("unexpected output: %+v", output)
+	}
+	if resp := get("/events?limit=0", nil); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", resp.StatusCode)
+	}
+	if resp := get("/events?after=12", nil); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", resp.StatusCode)
+	}
+
+	// the deployment block holds several events, pages of one go through all of them once
+	var all token.EventsOutput
+	get("/events?limit=1000", &all)
+	paged := []token.Event{}
+	for path := "/events?limit=1"; ; {
+		var page token.EventsOutput
+		get(path, &page)
+		paged = append(paged, page.Events...)
+		if page.Next == "" || len(paged) > len(all.Events) {
+			break
+		}
+		path = "/events?limit=1&after=" + page.Next
+	}
+	if len(all.Events) < 3 || all.Events[0].BlockNumber != all.Events[1].BlockNumber || !reflect.DeepEqual(paged, all.Events) {
+		t.Errorf("expected pages to add up to %+v, got %+v", all.Events, paged)
+	}
+}
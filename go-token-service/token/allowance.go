@@ -0,0 +1,67 @@
+package token
+
+import (
+	"log"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Approve sets spender's allowance over the signer's tokens to amount
+func (wlt *WhitelistableToken) Approve(i *AllowanceInput) (*TxOutput, error) {
+	job := wlt.Tracker.NewJob("approve", i.Spender)
+	return wlt.allowanceTx(job, i)
+}
+
+// IncreaseAllowance adds amount to spender's allowance over the signer's tokens
+func (wlt *WhitelistableToken) IncreaseAllowance(i *AllowanceInput) (*TxOutput, error) {
+	job := wlt.Tracker.NewJob("increaseAllowance", i.Spender)
+	return wlt.allowanceTx(job, i)
+}
+
+// DecreaseAllowance subtracts amount from spender's allowance over the signer's tokens
+func (wlt *WhitelistableToken) DecreaseAllowance(i *AllowanceInput) (*TxOutput, error) {
+	job := wlt.Tracker.NewJob("decreaseAllowance", i.Spender)
+
+	amount, err := ParseAmount(i.Amount)
+	if err != nil {
+		return wlt.Tracker.Fail(job, err)
+	}
+
+	// contract reverts with "decreased allowance below zero" otherwise
+	allowance, err := wlt.GetAllowance(wlt.CallerAddres.Hex(), i.Spender)
+	if err != nil {
+		return wlt.Tracker.Fail(job, err)
+	}
+	if allowance.Cmp(amount) < 0 {
+		return wlt.Tracker.Fail(job, InsufficientAllowanceError)
+	}
+
+	return wlt.allowanceTx(job, i)
+}
+
+// allowanceTx sends job's (address,uint256) allowance method - approve, increaseAllowance or decreaseAllowance
+func (wlt *WhitelistableToken) allowanceTx(job *Job, i *AllowanceInput) (*TxOutput, error) {
+	method := job.Operation
+
+	// check if address is valid
+	if ok := IsValidAddress(i.Spender); !ok {
+		return wlt.Tracker.Fail(job, InvalidAddressError)
+	}
+	spender := common.HexToAddress(i.Spender)
+
+	amount, err := ParseAmount(i.Amount)
+	if err != nil {
+		return wlt.Tracker.Fail(job, err)
+	}
+
+	output, err := wlt.transact(job, spender, amount)
+	if err != nil {
+		log.Printf("Audit: %s spender=%s amount=%s job=%s failed: %v", method, spender.Hex(), amount, job.ID, err)
+		return output, err
+	}
+
+	// allowance is handed to third parties, every change is logged
+	log.Printf("Audit: %s spender=%s amount=%s job=%s tx=%s", method, spender.Hex(), amount, job.ID, output.TransactionHash)
+
+	return output, nil
+}
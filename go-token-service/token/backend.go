@@ -0,0 +1,26 @@
+package token
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+var _ Backend = (*ethclient.Client)(nil)
+
+// Backend is everything WhitelistableToken needs from a node,
+// satisfied by *ethclient.Client and *backends.SimulatedBackend
+type Backend interface {
+	bind.ContractBackend
+	bind.DeployBackend
+	bind.PendingContractCaller
+
+	ChainID(ctx context.Context) (*big.Int, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+	TransactionByHash(ctx context.Context, hash common.Hash) (tx *types.Transaction, isPending bool, err error)
+}
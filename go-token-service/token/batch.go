@@ -0,0 +1,418 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+var (
+	BatchTooLargeError    = errors.New("Batch Too Large")
+	BatchBusyError        = errors.New("Too Many Batches In Flight")
+	BatchCancelledError   = errors.New("Batch Cancelled")
+	BatchJobFinishedError = errors.New("Batch Job Already Finished")
+	UnknownBatchJobError  = errors.New("Unknown Batch Job")
+)
+
+const (
+	defaultBatchConcurrency = 8
+	defaultBatchMaxSize     = 1000
+	defaultBatchMaxJobSize  = 10000
+	defaultBatchesInFlight  = 2
+	defaultBatchRetryAfter  = 10 * time.Second
+)
+
+// states of a BatchJob
+const (
+	BatchRunning   = "running"
+	BatchDone      = "done"
+	BatchCancelled = "cancelled" // items not sent when cancelled are skipped
+)
+
+// ItemWaiting state of batch items not processed yet
+const ItemWaiting = "waiting"
+
+// prepareItem prepares batch's item at index, returns a nil call for items skipped before becoming a job
+type prepareItem func(index int) (*call, error)
+
+// BatchLimits bounds the work batches put on the node
+type BatchLimits struct {
+	Concurrency int           // items of a batch checked and estimated at once
+	MaxSize     int           // items in a single /multiple request
+	MaxJobSize  int           // items in a single batch job
+	RetryAfter  time.Duration // suggested wait for refused batches
+
+	inFlight chan struct{} // one slot per batch running at once, batch jobs included
+}
+
+// NewBatchLimits allows inFlight batches at once
+func NewBatchLimits(concurrency, maxSize, maxJobSize, inFlight int, retryAfter time.Duration) *BatchLimits {
+	return &BatchLimits{concurrency, maxSize, maxJobSize, retryAfter, make(chan struct{}, inFlight)}
+}
+
+// acquire takes a slot for a batch without waiting
+func (l *BatchLimits) acquire(size, maxSize int) error {
+	if size > maxSize {
+		return BatchTooLargeError
+	}
+
+	select {
+	case l.inFlight <- struct{}{}:
+		return nil
+	default:
+		return BatchBusyError
+	}
+}
+
+// release frees batch's slot
+func (l *BatchLimits) release() {
+	<-l.inFlight
+}
+
+// BatchProgress counts of BatchJob's items by outcome
+type BatchProgress struct {
+	Total     int `json:"total"`
+	Waiting   int `json:"waiting"`
+	Sent      int `json:"sent"` // broadcast, pending or mined
+	Confirmed int `json:"confirmed"`
+	Failed    int `json:"failed"` // failed, replaced or dropped
+	Skipped   int `json:"skipped"`
+	Noop      int `json:"noop"` // nothing to send, e.g. already whitelisted
+	Cancelled int `json:"cancelled"`
+}
+
+// BatchJob batch processed in the background, items follow their jobs
+type BatchJob struct {
+	ID        string        `json:"id"`
+	Operation string        `json:"operation"`
+	State     string        `json:"state"`
+	Progress  BatchProgress `json:"progress"`
+	Created   time.Time     `json:"created"`
+	Finished  *time.Time    `json:"finished,omitempty"`
+	*TxMultiOutput
+
+	cancelled bool
+	journaled bool // a batch job, not a /multiple request
+}
+
+// newBatchJob waiting items for addresses
+func newBatchJob(operation string, addresses []string) *BatchJob {
+	b := &BatchJob{
+		ID:            newID(),
+		Operation:     operation,
+		State:         BatchRunning,
+		Created:       time.Now(),
+		TxMultiOutput: GetTxMultiOutput(len(addresses)),
+	}
+	for index, address := range addresses {
+		b.Transactions[index].Address = address
+		b.Transactions[index].State = ItemWaiting
+	}
+
+	return b
+}
+
+// isCancelled reports whether cancel was requested
+func (b *BatchJob) isCancelled() bool {
+	b.Lock()
+	defer b.Unlock()
+
+	return b.cancelled
+}
+
+// finish ends processing
+func (b *BatchJob) finish() {
+	b.Lock()
+	defer b.Unlock()
+
+	now := time.Now()
+	b.Finished = &now
+	b.State = BatchDone
+	if b.cancelled {
+		b.State = BatchCancelled
+	}
+}
+
+// expired finished longer than trackerRetention ago
+func (b *BatchJob) expired() bool {
+	return b.Finished != nil && time.Since(*b.Finished) > trackerRetention
+}
+
+// BatchJobs batch jobs by id, saved to journal so a restart keeps them. Finished ones are kept for a day.
+type BatchJobs struct {
+	jobs    map[string]*BatchJob
+	journal Journal
+	sync.Mutex
+}
+
+// NewBatchJobs empty registry saving to journal
+func NewBatchJobs(journal Journal) *BatchJobs {
+	return &BatchJobs{jobs: map[string]*BatchJob{}, journal: journal}
+}
+
+// add registers and saves the batch job, drops old finished ones
+func (r *BatchJobs) add(b *BatchJob) error {
+	b.journaled = true
+	if err := r.save(b, nil); err != nil {
+		return err
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	for id, job := range r.jobs {
+		job.Lock()
+		expired := job.expired()
+		job.Unlock()
+
+		if expired {
+			delete(r.jobs, id)
+		}
+	}
+	r.jobs[b.ID] = b
+	return nil
+}
+
+// save journals a snapshot of the batch job, items of calls being sent point to their jobs already
+func (r *BatchJobs) save(b *BatchJob, calls []*call) error {
+	if !b.journaled {
+		return nil
+	}
+
+	b.Lock()
+	snapshot := *b
+	snapshot.TxMultiOutput = &TxMultiOutput{&sync.Mutex{}, append([]TxItemOutput{}, b.Transactions...)}
+	b.Unlock()
+
+	for index, c := range calls {
+		if c != nil && snapshot.Transactions[index].JobID == "" {
+			snapshot.Transactions[index].JobID = c.job.ID
+		}
+	}
+
+	return r.journal.SaveBatch(&snapshot)
+}
+
+// restore loads journaled batch jobs. Ones a restart interrupted are finished: their items follow
+// the jobs they were sent as, items which never became a known job fail as interrupted.
+func (r *BatchJobs) restore(tracker *Tracker) error {
+	batches, err := r.journal.LoadBatches()
+	if err != nil {
+		return err
+	}
+
+	for _, b := range batches {
+		if b.expired() {
+			continue
+		}
+		if b.TxMultiOutput == nil {
+			b.TxMultiOutput = GetTxMultiOutput(0)
+		}
+		b.Mutex, b.journaled = &sync.Mutex{}, true
+
+		if b.Finished == nil {
+			for index, item := range b.Transactions {
+				if _, ok := tracker.Job(item.JobID); ok || item.State != ItemWaiting {
+					continue
+				}
+				b.Transactions[index].State = JobFailed
+				b.Transactions[index].Error = &TxError{ErrorCode(InterruptedError), InterruptedError.Error()}
+			}
+			b.finish()
+			if err := r.save(b, nil); err != nil {
+				return err
+			}
+		}
+
+		r.Lock()
+		r.jobs[b.ID] = b
+		r.Unlock()
+	}
+
+	return nil
+}
+
+// get batch job by id
+func (r *BatchJobs) get(id string) (*BatchJob, bool) {
+	r.Lock()
+	defer r.Unlock()
+
+	b, ok := r.jobs[id]
+	return b, ok
+}
+
+// batch processes a /multiple request right away, see process
+func (wlt *WhitelistableToken) batch(addresses []string, prepare prepareItem) (*TxMultiOutput, error) {
+	if err := wlt.Batches.acquire(len(addresses), wlt.Batches.MaxSize); err != nil {
+		return nil, err
+	}
+	defer wlt.Batches.release()
+
+	b := newBatchJob("", addresses)
+	wlt.process(b, prepare)
+
+	return b.TxMultiOutput, nil
+}
+
+// StartMintJob mints to all given addresses in the background
+func (wlt *WhitelistableToken) StartMintJob(i *MintMultiInput) (*BatchJob, error) {
+	addresses, prepare := wlt.mintItems(i)
+	return wlt.startBatchJob("mint", addresses, prepare)
+}
+
+// StartWhitelistJob grants WhitelistedRole to all given addresses in the background
+func (wlt *WhitelistableToken) StartWhitelistJob(i *WhitelistMultiInput) (*BatchJob, error) {
+	addresses, prepare := wlt.roleItems("grantRole", i)
+	return wlt.startBatchJob("whitelist", addresses, prepare)
+}
+
+// startBatchJob registers and processes the batch in the background, returns its first snapshot
+func (wlt *WhitelistableToken) startBatchJob(operation string, addresses []string, prepare prepareItem) (*BatchJob, error) {
+	if err := wlt.Batches.acquire(len(addresses), wlt.Batches.MaxJobSize); err != nil {
+		return nil, err
+	}
+
+	b := newBatchJob(operation, addresses)
+	if err := wlt.BatchJobs.add(b); err != nil {
+		wlt.Batches.release()
+		return nil, err
+	}
+
+	go func() {
+		defer wlt.Batches.release()
+		wlt.process(b, prepare)
+	}()
+
+	return wlt.GetBatchJob(b.ID)
+}
+
+// GetBatchJob snapshot of the batch job with items' current states
+func (wlt *WhitelistableToken) GetBatchJob(id string) (*BatchJob, error) {
+	b, ok := wlt.BatchJobs.get(id)
+	if !ok {
+		return nil, UnknownBatchJobError
+	}
+
+	b.Lock()
+	snapshot := *b
+	snapshot.TxMultiOutput = &TxMultiOutput{&sync.Mutex{}, append([]TxItemOutput{}, b.Transactions...)}
+	b.Unlock()
+
+	progress := BatchProgress{Total: len(snapshot.Transactions)}
+	for index, item := range snapshot.Transactions {
+		// sent items move on after the batch is done with them
+		if job, ok := wlt.Tracker.Job(item.JobID); ok {
+			snapshot.Transactions[index].TxOutput = *wlt.Tracker.Output(job)
+			item = snapshot.Transactions[index]
+		}
+
+		switch {
+		case item.Error != nil && item.Error.Code == CodeCancelled:
+			progress.Cancelled++
+		case item.State == ItemWaiting:
+			progress.Waiting++
+		case item.State == ItemSkipped:
+			progress.Skipped++
+		case item.State == JobConfirmed:
+			progress.Confirmed++
+		case item.State == JobNoop:
+			progress.Noop++
+		case item.State == JobFailed || item.State == JobReplaced || item.State == JobDropped:
+			progress.Failed++
+		default:
+			progress.Sent++
+		}
+	}
+	snapshot.Progress = progress
+
+	return &snapshot, nil
+}
+
+// CancelBatchJob stops the batch job, items not sent yet are skipped
+func (wlt *WhitelistableToken) CancelBatchJob(id string) (*BatchJob, error) {
+	b, ok := wlt.BatchJobs.get(id)
+	if !ok {
+		return nil, UnknownBatchJobError
+	}
+
+	b.Lock()
+	finished := b.Finished != nil
+	b.cancelled = b.cancelled || !finished
+	b.Unlock()
+
+	if finished {
+		return nil, BatchJobFinishedError
+	}
+
+	return wlt.GetBatchJob(id)
+}
+
+// process prepares batch's items on a pool of Batches.Concurrency workers, then sends prepared calls
+// one by one in input order, so nonces follow the input. Cancelling stops both phases.
+// Batch jobs are saved before sending and once finished.
+func (wlt *WhitelistableToken) process(b *BatchJob, prepare prepareItem) {
+	defer func() {
+		b.finish()
+		if err := wlt.BatchJobs.save(b, nil); err != nil {
+			log.Printf("Journal: can't save batch job %s: %v", b.ID, err)
+		}
+	}()
+
+	size := len(b.Transactions)
+	calls := make([]*call, size)
+	errs := make([]error, size)
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < wlt.Batches.Concurrency && worker < size; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				if b.isCancelled() {
+					errs[index] = BatchCancelledError
+					continue
+				}
+				calls[index], errs[index] = prepare(index)
+			}
+		}()
+	}
+	for index := 0; index < size; index++ {
+		indexes <- index
+	}
+	close(indexes)
+	wg.Wait()
+
+	// a restart finds the jobs of items sent from here on
+	if err := wlt.BatchJobs.save(b, calls); err != nil {
+		log.Printf("Journal: can't save batch job %s: %v", b.ID, err)
+	}
+
+	// deferring for fees happens once here, a send waiting on its own would hold up the rest
+	sending := false
+	for index, c := range calls {
+		sending = sending || (c != nil && !c.done && errs[index] == nil)
+	}
+	if ceiling, ok := wlt.Gas.(*CeilingPricer); ok && sending && !b.isCancelled() {
+		ceiling.waitForFees(context.Background(), *wlt.TransactOpts)
+	}
+	ctx := withoutDefer(context.Background())
+
+	for index, c := range calls {
+		address := b.Transactions[index].Address
+
+		switch {
+		case c == nil:
+			b.Skip(index, address, errs[index])
+		case errs[index] == nil && !c.done && b.isCancelled():
+			output, _ := wlt.Tracker.Fail(c.job, BatchCancelledError)
+			b.Set(index, output)
+		default:
+			// error is part of the output
+			output, _ := wlt.runContext(ctx, c, errs[index])
+			b.Set(index, output)
+		}
+	}
+}
@@ -1,17 +1,76 @@
 package token
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
+	"math/big"
 	"os"
 	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	ConfigNotObjectError = errors.New("Config Is Not A JSON Object")
 )
 
 type appConfig struct {
-	PrivateKey      string `json:"privateKey"`
-	Network         string `json:"network"`
-	InfuraKey       string `json:"infuraKey"`
-	ContractAddress string `json:"contractAddress"`
+	PrivateKey      string        `json:"privateKey"`
+	RPCURL          string        `json:"rpcUrl"`    // any http(s), ws(s) or ipc endpoint, takes precedence over network and infuraKey
+	Network         string        `json:"network"`   // infura network, used when rpcUrl is empty
+	InfuraKey       string        `json:"infuraKey"` // infura project id, used when rpcUrl is empty
+	ContractAddress string        `json:"contractAddress"`
+	RolesAuth       credentials   `json:"rolesAuth"` // basic auth for /roles/ management, disabled when empty
+	Tracker         trackerConfig `json:"tracker"`
+	Gas             gasConfig     `json:"gas"`
+	Batch           batchConfig   `json:"batch"`
+	Indexer         indexerConfig `json:"indexer"`
+}
+
+type trackerConfig struct {
+	Confirmations uint64 `json:"confirmations"` // blocks on top of the mined one, 1 = mined is final, default 1
+	PollInterval  string `json:"pollInterval"`  // time.ParseDuration format, default 5s
+	Journal       string `json:"journal"`       // path of the jobs' journal file, kept in memory only when empty
+}
+
+type gasConfig struct {
+	Strategy             string  `json:"strategy"`             // auto, legacy, eip1559, fixed or percentile, default auto
+	GasPrice             string  `json:"gasPrice"`             // fixed: legacy gas price in wei
+	MaxFeePerGas         string  `json:"maxFeePerGas"`         // fixed: EIP-1559 fee cap in wei
+	MaxPriorityFeePerGas string  `json:"maxPriorityFeePerGas"` // fixed: EIP-1559 tip in wei
+	Percentile           float64 `json:"percentile"`           // percentile: tip percentile of recent blocks, default 50
+	Blocks               uint64  `json:"blocks"`               // percentile: recent blocks to look at, default 20
+	Ceiling              string  `json:"ceiling"`              // max wei per gas ever paid, no limit when empty
+	OnCeiling            string  `json:"onCeiling"`            // refuse or defer sends above ceiling, default refuse
+	DeferTimeout         string  `json:"deferTimeout"`         // time.ParseDuration format, default 5m
+
+	LimitMultiplier float64           `json:"limitMultiplier"` // gas limit is estimateGas times this, default 1.2
+	LimitCaps       map[string]uint64 `json:"limitCaps"`       // max gas limit per operation, e.g. {"mint": 150000}
+}
+
+type batchConfig struct {
+	Concurrency int    `json:"concurrency"` // items of a batch estimated at once, default 8
+	MaxSize     int    `json:"maxSize"`     // items per /multiple request, default 1000
+	MaxJobSize  int    `json:"maxJobSize"`  // items per batch job, default 10000
+	InFlight    int    `json:"inFlight"`    // /multiple requests and batch jobs handled at once, default 2
+	RetryAfter  string `json:"retryAfter"`  // time.ParseDuration format, Retry-After of refused requests, default 10s
+}
+
+type indexerConfig struct {
+	Enabled      bool   `json:"enabled"`
+	FromBlock    uint64 `json:"fromBlock"`    // contract's deployment block, backfill starts there
+	ChunkSize    uint64 `json:"chunkSize"`    // blocks per log query, default 2000
+	Store        string `json:"store"`        // path of the events file, kept in memory only when empty
+	PollInterval string `json:"pollInterval"` // time.ParseDuration format, new blocks are checked that often, default 5s
+}
+
+type credentials struct {
+	User string `json:"user"`
+	Pass string `json:"pass"`
 }
 
 var config *appConfig
@@ -49,3 +108,203 @@ func readConfig() (*appConfig, error) {
 
 	return config, nil
 }
+
+// rpcURL node endpoint - explicit rpcUrl or infura's one
+func (c *appConfig) rpcURL() string {
+	if c.RPCURL != "" {
+		return c.RPCURL
+	}
+
+	return fmt.Sprintf("https://%s.infura.io/v3/%s", c.Network, c.InfuraKey)
+}
+
+// confirmations required by Tracker, at least 1
+func (c *trackerConfig) confirmations() uint64 {
+	if c.Confirmations == 0 {
+		return 1
+	}
+
+	return c.Confirmations
+}
+
+// pollInterval of Tracker, defaults to 5s
+func (c *trackerConfig) pollInterval() time.Duration {
+	interval, err := time.ParseDuration(c.PollInterval)
+	if err != nil || interval <= 0 {
+		return 5 * time.Second
+	}
+
+	return interval
+}
+
+// journal of Tracker, the file is created when missing
+func (c *trackerConfig) journal() (Journal, error) {
+	if c.Journal == "" {
+		return NewMemoryJournal(), nil
+	}
+
+	return OpenFileJournal(c.Journal)
+}
+
+// indexer of the contract at address with its roles, nil when disabled
+func (c *indexerConfig) indexer(backend Backend, address common.Address, roles map[string][32]byte) (*Indexer, error) {
+	if !c.Enabled {
+		return nil, nil
+	}
+
+	chunkSize := c.ChunkSize
+	if chunkSize == 0 {
+		chunkSize = defaultIndexerChunkSize
+	}
+	interval, err := time.ParseDuration(c.PollInterval)
+	if err != nil || interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	var store EventStore = NewMemoryEventStore()
+	if c.Store != "" {
+		if store, err = OpenFileEventStore(c.Store); err != nil {
+			return nil, err
+		}
+	}
+
+	return NewIndexer(backend, address, roles, store, c.FromBlock, chunkSize, interval)
+}
+
+// limits batch settings with defaults applied
+func (c *batchConfig) limits() *BatchLimits {
+	concurrency, maxSize, maxJobSize, inFlight := c.Concurrency, c.MaxSize, c.MaxJobSize, c.InFlight
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+	if maxSize <= 0 {
+		maxSize = defaultBatchMaxSize
+	}
+	if maxJobSize <= 0 {
+		maxJobSize = defaultBatchMaxJobSize
+	}
+	if inFlight <= 0 {
+		inFlight = defaultBatchesInFlight
+	}
+
+	retryAfter, err := time.ParseDuration(c.RetryAfter)
+	if err != nil || retryAfter <= 0 {
+		retryAfter = defaultBatchRetryAfter
+	}
+
+	return NewBatchLimits(concurrency, maxSize, maxJobSize, inFlight, retryAfter)
+}
+
+// percentile of tips used by the percentile strategy, defaults to 50
+func (c *gasConfig) percentile() float64 {
+	if c.Percentile <= 0 || c.Percentile > 100 {
+		return defaultTipPercentile
+	}
+
+	return c.Percentile
+}
+
+// blocks of fee history used by the percentile strategy, defaults to 20
+func (c *gasConfig) blocks() uint64 {
+	if c.Blocks == 0 {
+		return defaultFeeHistoryBlocks
+	}
+
+	return c.Blocks
+}
+
+// deferTimeout how long sends above the ceiling wait, defaults to 5m
+func (c *gasConfig) deferTimeout() time.Duration {
+	timeout, err := time.ParseDuration(c.DeferTimeout)
+	if err != nil || timeout <= 0 {
+		return defaultDeferTimeout
+	}
+
+	return timeout
+}
+
+// limits gas limit settings with defaults applied
+func (c *gasConfig) limits() *GasLimits {
+	multiplier := c.LimitMultiplier
+	if multiplier < 1 {
+		multiplier = defaultLimitMultiplier
+	}
+
+	return &GasLimits{multiplier, c.LimitCaps}
+}
+
+// wei parses one of the wei settings, nil when it's not set
+func (c *gasConfig) wei(value string) (*big.Int, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	return ParseAmount(value)
+}
+
+// WriteContractAddress sets contractAddress in the config file, the rest of the file is kept byte for byte -
+// only the value is replaced, or the key is added first when it's missing
+func WriteContractAddress(filePath, address string) error {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+
+	byteValue, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+
+	value, err := json.Marshal(address)
+	if err != nil {
+		return err
+	}
+
+	// offsets of the top-level value - the last one counts, like for json.Unmarshal
+	decoder := json.NewDecoder(bytes.NewReader(byteValue))
+	if delim, err := decoder.Token(); err != nil || delim != json.Delim('{') {
+		return ConfigNotObjectError
+	}
+	open := decoder.InputOffset()
+	start, end, keys := int64(-1), int64(-1), 0
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return err
+		}
+		if key == "contractAddress" {
+			end = decoder.InputOffset()
+			start = end - int64(len(raw))
+		}
+		keys++
+	}
+	if _, err := decoder.Token(); err != nil {
+		return err
+	}
+
+	updated := []byte{}
+	if start >= 0 {
+		updated = append(updated, byteValue[:start]...)
+		updated = append(updated, value...)
+		updated = append(updated, byteValue[end:]...)
+	} else {
+		// added in front of the other keys, indented like the first of them
+		rest := byteValue[open:]
+		indent := rest[:len(rest)-len(bytes.TrimLeft(rest, " \t\r\n"))]
+		entry := append([]byte(`"contractAddress": `), value...)
+		if keys > 0 {
+			entry = append(entry, ',')
+		}
+
+		updated = append(updated, byteValue[:open]...)
+		updated = append(updated, indent...)
+		updated = append(updated, entry...)
+		updated = append(updated, rest...)
+	}
+
+	return ioutil.WriteFile(filePath, updated, info.Mode())
+}
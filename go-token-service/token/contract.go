@@ -0,0 +1,131 @@
+package token
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"ERC20Whitelistable/go-token-service/contracts"
+)
+
+var parsedABI abi.ABI
+var parsedABIErr error
+var parseABIOnce sync.Once
+
+// tokenABI parses contract's ABI only once
+func tokenABI() (abi.ABI, error) {
+	parseABIOnce.Do(func() {
+		parsedABI, parsedABIErr = abi.JSON(strings.NewReader(token.TokenABI))
+	})
+
+	return parsedABI, parsedABIErr
+}
+
+// callMsg calldata of any contract method packed from the ABI, sent by the signer
+func (wlt *WhitelistableToken) callMsg(method string, args ...interface{}) (ethereum.CallMsg, error) {
+	parsed, err := tokenABI()
+	if err != nil {
+		return ethereum.CallMsg{}, err
+	}
+
+	data, err := parsed.Pack(method, args...)
+	if err != nil {
+		return ethereum.CallMsg{}, err
+	}
+
+	return ethereum.CallMsg{
+		From: *wlt.CallerAddres,
+		To:   wlt.ContractAddress,
+		Data: data,
+	}, nil
+}
+
+// estimate Estimate Gas for any contract method, fails the same way the transaction would
+func (wlt *WhitelistableToken) estimate(method string, args ...interface{}) (uint64, error) {
+	msg, err := wlt.callMsg(method, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return wlt.Backend.EstimateGas(context.Background(), msg)
+}
+
+// Simulate dry-runs any contract method from the signer on top of pending state, nothing is sent.
+// Returns method's unpacked outputs or the revert.
+func (wlt *WhitelistableToken) Simulate(method string, args ...interface{}) ([]interface{}, error) {
+	msg, err := wlt.callMsg(method, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := wlt.Backend.PendingCallContract(context.Background(), msg)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, _ := tokenABI()
+	return parsed.Unpack(method, data)
+}
+
+// call contract method prepared for its job - checked and estimated, by its before step at the latest
+type call struct {
+	job    *Job
+	args   []interface{}                   // arguments of job's operation
+	done   bool                            // nothing to send - job of an earlier request with the same idempotency key or a no-op
+	before func(ctx context.Context) error // runs in send order right before sending, its error fails the job instead
+}
+
+// prepare estimates job's contract method - job's operation - with args
+func (wlt *WhitelistableToken) prepare(job *Job, args ...interface{}) (*call, error) {
+	// check estimateGas
+	gas, err := wlt.estimate(job.Operation, args...)
+	if err != nil {
+		return wlt.fail(job, err)
+	}
+	wlt.Tracker.Estimated(job, gas)
+
+	return &call{job, args, false, nil}, nil
+}
+
+// fail fails the job while preparing its call
+func (wlt *WhitelistableToken) fail(job *Job, err error) (*call, error) {
+	wlt.Tracker.Fail(job, err)
+	return &call{job: job}, err
+}
+
+// run sends a prepared call, calls which failed to prepare or have nothing to send are only reported
+func (wlt *WhitelistableToken) run(c *call, err error) (*TxOutput, error) {
+	return wlt.runContext(context.Background(), c, err)
+}
+
+// runContext is run with ctx for pricing the send
+func (wlt *WhitelistableToken) runContext(ctx context.Context, c *call, err error) (*TxOutput, error) {
+	if err != nil || c.done {
+		return wlt.Tracker.Output(c.job), err
+	}
+	if c.before != nil {
+		if err := c.before(ctx); err != nil {
+			return wlt.Tracker.Fail(c.job, err)
+		}
+	}
+
+	raw := &token.TokenRaw{Contract: wlt.Token}
+	_, err = wlt.send(ctx, c.job, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return raw.Transact(opts, c.job.Operation, c.args...)
+	})
+	if err != nil {
+		return wlt.Tracker.Fail(c.job, err)
+	}
+
+	return wlt.Tracker.Output(c.job), nil
+}
+
+// transact estimates and sends job's contract method with args
+func (wlt *WhitelistableToken) transact(job *Job, args ...interface{}) (*TxOutput, error) {
+	return wlt.run(wlt.prepare(job, args...))
+}
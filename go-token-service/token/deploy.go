@@ -0,0 +1,89 @@
+package token
+
+import (
+	"context"
+	"crypto/ecdsa"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"ERC20Whitelistable/go-token-service/contracts"
+)
+
+// DeployConfigured deploys ERC20Whitelistable with the configured node and signer
+func DeployConfigured(i *DeployInput) (*DeployOutput, error) {
+	rpcClient, privateKey, err := dialConfig(GetConfig())
+	if err != nil {
+		return nil, err
+	}
+	defer rpcClient.Close()
+
+	wlt, output, err := Deploy(ethclient.NewClient(rpcClient), privateKey, i)
+	if wlt != nil {
+		wlt.Close()
+	}
+	return output, err
+}
+
+// Deploy deploys ERC20Whitelistable, waits until it's mined and grants initial roles.
+// Deployer holds all of the contract's roles. Grants are sent but not waited for.
+func Deploy(backend Backend, privateKey *ecdsa.PrivateKey, i *DeployInput) (*WhitelistableToken, *DeployOutput, error) {
+	opts, err := newTransactor(backend, privateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// same fees and ceiling as every other transaction
+	gas, err := gasPricer(backend, &GetConfig().Gas)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := gas.Price(context.Background(), opts); err != nil {
+		return nil, nil, err
+	}
+
+	address, tx, _, err := token.DeployToken(opts, backend)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := bind.WaitDeployed(context.Background(), backend, tx); err != nil {
+		return nil, nil, err
+	}
+
+	receipt, err := backend.TransactionReceipt(context.Background(), tx.Hash())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	output := &DeployOutput{
+		ContractAddress: address.Hex(),
+		TransactionHash: tx.Hash().Hex(),
+		BlockNumber:     receipt.BlockNumber.Uint64(),
+		Grants:          []TxOutput{},
+	}
+
+	wlt, err := NewWhitelistableToken(backend, privateKey, address)
+	if err != nil {
+		return nil, output, err
+	}
+
+	grants := []struct {
+		role      string
+		addresses []string
+	}{
+		{MinterRoleName, i.Minters},
+		{WhitelistedRoleName, i.Whitelisted},
+	}
+	for _, g := range grants {
+		for _, addr := range g.addresses {
+			grant, err := wlt.GrantRole(&RoleInput{Role: g.role, Address: addr})
+			output.Grants = append(output.Grants, *grant)
+			if err != nil {
+				return wlt, output, err
+			}
+		}
+	}
+
+	return wlt, output, nil
+}
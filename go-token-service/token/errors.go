@@ -0,0 +1,84 @@
+package token
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	EmptyAddressError = errors.New("Empty Address")
+)
+
+// machine readable error codes of TxError
+const (
+	CodeInvalidAddress        = "invalid_address"
+	CodeEmptyAddress          = "empty_address"
+	CodeInvalidAmount         = "invalid_amount"
+	CodeUnknownRole           = "unknown_role"
+	CodeNotWhitelisted        = "not_whitelisted"
+	CodeInsufficientBalance   = "insufficient_balance"
+	CodeInsufficientAllowance = "insufficient_allowance"
+	CodeRoleSafeguard         = "role_safeguard"     // last admin or signer's own admin role
+	CodeGasPriceTooHigh       = "gas_price_too_high" // above the configured ceiling
+	CodeGasCap                = "gas_cap_exceeded"   // estimate above operation's cap
+	CodeEstimationReverted    = "estimation_reverted"
+	CodeReverted              = "reverted" // mined but reverted
+	CodeNonce                 = "nonce_error"
+	CodeCancelled             = "cancelled"   // batch job cancelled before sending
+	CodeInterrupted           = "interrupted" // restart before sending
+	CodeIdempotencyKeyReused  = "idempotency_key_reused"
+	CodeRPC                   = "rpc_error"
+)
+
+// TxError why an operation failed
+type TxError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ErrorCode classifies err into one of the Code constants, anything unknown is an rpc failure
+func ErrorCode(err error) string {
+	switch err {
+	case InvalidAddressError:
+		return CodeInvalidAddress
+	case EmptyAddressError:
+		return CodeEmptyAddress
+	case InvalidAmountError, NegativeAmountError, TooManyDecimalsError, UnknownUnitsError:
+		return CodeInvalidAmount
+	case UnknownRoleError:
+		return CodeUnknownRole
+	case NotWhitelistedError, GrantRevertedError:
+		return CodeNotWhitelisted
+	case InsufficientBalanceError:
+		return CodeInsufficientBalance
+	case InsufficientAllowanceError:
+		return CodeInsufficientAllowance
+	case LastAdminError, SelfAdminError:
+		return CodeRoleSafeguard
+	case GasPriceTooHighError:
+		return CodeGasPriceTooHigh
+	case GasCapError:
+		return CodeGasCap
+	case BatchCancelledError:
+		return CodeCancelled
+	case InterruptedError:
+		return CodeInterrupted
+	case IdempotencyKeyReusedError:
+		return CodeIdempotencyKeyReused
+	}
+
+	if isNonceError(err) {
+		return CodeNonce
+	}
+	if isRevertError(err) {
+		return CodeEstimationReverted
+	}
+
+	return CodeRPC
+}
+
+// isRevertError reports node errors of a call or estimate which would revert
+func isRevertError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "revert") || strings.Contains(msg, "always failing transaction")
+}
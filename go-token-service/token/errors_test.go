@@ -0,0 +1,28 @@
+package token_test
+
+import (
+	"errors"
+	"testing"
+
+	"ERC20Whitelistable/go-token-service/token"
+)
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		code string
+	}{
+		{token.InvalidAddressError, token.CodeInvalidAddress},
+		{token.TooManyDecimalsError, token.CodeInvalidAmount},
+		{token.NotWhitelistedError, token.CodeNotWhitelisted},
+		{errors.New("execution reverted: ERC20Whitelistable: recipient not whitelisted"), token.CodeEstimationReverted},
+		{errors.New("nonce too low: address 0x00, tx: 1 state: 2"), token.CodeNonce},
+		{errors.New("dial tcp 127.0.0.1:8545: connect: connection refused"), token.CodeRPC},
+	}
+
+	for _, test := range tests {
+		if code := token.ErrorCode(test.err); code != test.code {
+			t.Errorf("%v: expected %s, got %s", test.err, test.code, code)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package token
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	InvalidEventPositionError = errors.New("Invalid Event Position")
+)
+
+// names of indexed events
+const (
+	EventTransfer         = "Transfer"
+	EventRoleGranted      = "RoleGranted"
+	EventRoleRevoked      = "RoleRevoked"
+	EventRoleAdminChanged = "RoleAdminChanged"
+)
+
+// recentBlocks block refs an EventStore keeps to find where a reorg forked off
+const recentBlocks = 128
+
+// Event decoded contract event, fields not used by the event are empty
+type Event struct {
+	Name        string `json:"name"`
+	BlockNumber uint64 `json:"blockNumber"`
+	BlockHash   string `json:"blockHash"`
+	TxHash      string `json:"txHash"`
+	LogIndex    uint   `json:"logIndex"`
+
+	From  string `json:"from,omitempty"` // Transfer, zero address on mint
+	To    string `json:"to,omitempty"`
+	Value string `json:"value,omitempty"`
+
+	Role              string `json:"role,omitempty"` // role events - name of known roles, hex otherwise
+	Account           string `json:"account,omitempty"`
+	Sender            string `json:"sender,omitempty"`
+	PreviousAdminRole string `json:"previousAdminRole,omitempty"` // RoleAdminChanged
+	NewAdminRole      string `json:"newAdminRole,omitempty"`
+}
+
+// BlockRef block the indexer has seen
+type BlockRef struct {
+	Number uint64 `json:"number"`
+	Hash   string `json:"hash"`
+}
+
+// EventPosition event's place on the chain, a page of events goes on after the last one of the previous page
+type EventPosition struct {
+	BlockNumber uint64
+	LogIndex    uint
+}
+
+// ParseEventPosition position written as block:logIndex
+func ParseEventPosition(position string) (*EventPosition, error) {
+	parts := strings.Split(position, ":")
+	if len(parts) != 2 {
+		return nil, InvalidEventPositionError
+	}
+
+	block, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return nil, InvalidEventPositionError
+	}
+	index, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return nil, InvalidEventPositionError
+	}
+
+	return &EventPosition{block, uint(index)}, nil
+}
+
+// String block:logIndex
+func (p EventPosition) String() string {
+	return fmt.Sprintf("%d:%d", p.BlockNumber, p.LogIndex)
+}
+
+// EventFilter selects stored events, zero values match everything
+type EventFilter struct {
+	Name      string
+	Address   string // from, to or account
+	FromBlock uint64
+	ToBlock   uint64
+	After     *EventPosition // only events past it
+	Limit     int
+}
+
+// matches reports whether event passes the filter
+func (f *EventFilter) matches(e *Event) bool {
+	address := strings.ToLower(f.Address)
+
+	return (f.Name == "" || f.Name == e.Name) &&
+		(address == "" || address == strings.ToLower(e.From) || address == strings.ToLower(e.To) || address == strings.ToLower(e.Account)) &&
+		e.BlockNumber >= f.FromBlock &&
+		(f.ToBlock == 0 || e.BlockNumber <= f.ToBlock) &&
+		(f.After == nil || e.BlockNumber > f.After.BlockNumber || (e.BlockNumber == f.After.BlockNumber && e.LogIndex > f.After.LogIndex))
+}
+
+// EventStore keeps indexed events with the cursor - the last indexed block - and refs of recent blocks
+type EventStore interface {
+	Append(events []Event, head BlockRef) error // events up to head, head becomes the cursor
+	Rollback(to *BlockRef) error                // drops events above to, to becomes the cursor - nil drops all
+	Cursor() (*BlockRef, error)                 // nil before anything was indexed
+	Recent() ([]BlockRef, error)                // ascending, the cursor is the last one
+	Events(filter *EventFilter) ([]Event, error)
+	Close() error
+}
+
+// MemoryEventStore keeps events only as long as the process runs
+type MemoryEventStore struct {
+	events []Event // ascending by block and log index
+	recent []BlockRef
+
+	sync.Mutex
+}
+
+// NewMemoryEventStore empty store
+func NewMemoryEventStore() *MemoryEventStore {
+	return &MemoryEventStore{events: []Event{}, recent: []BlockRef{}}
+}
+
+// Append adds events of blocks after the cursor
+func (s *MemoryEventStore) Append(events []Event, head BlockRef) error {
+	s.Lock()
+	defer s.Unlock()
+
+	s.events = append(s.events, events...)
+	s.recent = append(s.recent, head)
+	if len(s.recent) > recentBlocks {
+		s.recent = s.recent[len(s.recent)-recentBlocks:]
+	}
+	return nil
+}
+
+// Rollback drops events and refs above to
+func (s *MemoryEventStore) Rollback(to *BlockRef) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if to == nil {
+		s.events, s.recent = []Event{}, []BlockRef{}
+		return nil
+	}
+
+	for len(s.events) > 0 && s.events[len(s.events)-1].BlockNumber > to.Number {
+		s.events = s.events[:len(s.events)-1]
+	}
+	for len(s.recent) > 0 && s.recent[len(s.recent)-1].Number >= to.Number {
+		s.recent = s.recent[:len(s.recent)-1]
+	}
+	s.recent = append(s.recent, *to)
+	return nil
+}
+
+// Cursor last indexed block
+func (s *MemoryEventStore) Cursor() (*BlockRef, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if len(s.recent) == 0 {
+		return nil, nil
+	}
+	cursor := s.recent[len(s.recent)-1]
+	return &cursor, nil
+}
+
+// Recent refs of recently indexed blocks
+func (s *MemoryEventStore) Recent() ([]BlockRef, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	return append([]BlockRef{}, s.recent...), nil
+}
+
+// Events stored events passing the filter, oldest first
+func (s *MemoryEventStore) Events(filter *EventFilter) ([]Event, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	events := []Event{}
+	for index := range s.events {
+		if filter.Limit > 0 && len(events) == filter.Limit {
+			break
+		}
+		if filter.matches(&s.events[index]) {
+			events = append(events, s.events[index])
+		}
+	}
+	return events, nil
+}
+
+// Close no-op
+func (s *MemoryEventStore) Close() error {
+	return nil
+}
+
+// eventRecord line of FileEventStore - an append, a rollback or a compacted snapshot
+type eventRecord struct {
+	Events   []Event    `json:"events,omitempty"`
+	Head     *BlockRef  `json:"head,omitempty"`
+	Rollback *BlockRef  `json:"rollback,omitempty"`
+	Reset    bool       `json:"reset,omitempty"`  // rollback of everything
+	Recent   []BlockRef `json:"recent,omitempty"` // snapshot
+}
+
+// FileEventStore appends changes as JSON lines, synced to disk before they return.
+// Opening compacts the file into a single snapshot unless a broken line was skipped, see openJSONLines.
+type FileEventStore struct {
+	lines *jsonLines
+
+	*MemoryEventStore
+}
+
+// OpenFileEventStore opens or creates the store at path
+func OpenFileEventStore(path string) (*FileEventStore, error) {
+	memory := NewMemoryEventStore()
+	intact, end, err := readJSONLines(path, func(raw json.RawMessage) error {
+		var record eventRecord
+		if err := json.Unmarshal(raw, &record); err != nil {
+			return err
+		}
+		memory.replay(&record)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	records := []interface{}{}
+	if len(memory.recent) > 0 {
+		records = append(records, &eventRecord{Events: memory.events, Recent: memory.recent})
+	}
+
+	lines, err := openJSONLines(path, intact, end, records)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileEventStore{lines, memory}, nil
+}
+
+// replay applies a record read back from the file
+func (s *MemoryEventStore) replay(record *eventRecord) {
+	switch {
+	case record.Recent != nil:
+		s.events, s.recent = append(s.events, record.Events...), record.Recent
+	case record.Head != nil:
+		s.Append(record.Events, *record.Head)
+	case record.Rollback != nil || record.Reset:
+		s.Rollback(record.Rollback)
+	}
+}
+
+// Append adds events of blocks after the cursor
+func (s *FileEventStore) Append(events []Event, head BlockRef) error {
+	if err := s.lines.append(&eventRecord{Events: events, Head: &head}); err != nil {
+		return err
+	}
+
+	return s.MemoryEventStore.Append(events, head)
+}
+
+// Rollback drops events and refs above to
+func (s *FileEventStore) Rollback(to *BlockRef) error {
+	if err := s.lines.append(&eventRecord{Rollback: to, Reset: to == nil}); err != nil {
+		return err
+	}
+
+	return s.MemoryEventStore.Rollback(to)
+}
+
+// Close closes the file
+func (s *FileEventStore) Close() error {
+	return s.lines.Close()
+}
@@ -0,0 +1,309 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/big"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+var (
+	GasPriceTooHighError       = errors.New("Gas Price Above Ceiling")
+	NotEIP1559Error            = errors.New("Chain doesn't support EIP-1559")
+	FeeHistoryUnsupportedError = errors.New("Node doesn't support fee history")
+	UnknownGasStrategyError    = errors.New("Unknown Gas Strategy")
+	FixedGasError              = errors.New("Fixed gas needs either gasPrice or maxFeePerGas with maxPriorityFeePerGas")
+	GasCapError                = errors.New("Gas Estimate Above Operation's Cap")
+)
+
+// gas pricing strategies
+const (
+	GasAuto       = "auto"       // eip1559 when the head block has a base fee, legacy otherwise
+	GasLegacy     = "legacy"     // node's suggested gas price
+	GasEIP1559    = "eip1559"    // base fee plus node's suggested tip
+	GasFixed      = "fixed"      // configured values
+	GasPercentile = "percentile" // base fee plus a percentile of recent blocks' tips
+)
+
+const (
+	defaultFeeHistoryBlocks = 20
+	defaultTipPercentile    = 50
+	defaultDeferTimeout     = 5 * time.Minute
+	defaultLimitMultiplier  = 1.2
+	deferInterval           = 15 * time.Second
+)
+
+// GasPricer sets fee fields of a single transaction's TransactOpts - GasPrice or GasFeeCap and GasTipCap
+type GasPricer interface {
+	Price(ctx context.Context, opts *bind.TransactOpts) error
+}
+
+// feeHistoryBackend nodes with eth_feeHistory, *ethclient.Client has it
+type feeHistoryBackend interface {
+	FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
+}
+
+// LegacyPricer pays node's suggested gas price
+type LegacyPricer struct {
+	Backend Backend
+}
+
+func (p *LegacyPricer) Price(ctx context.Context, opts *bind.TransactOpts) error {
+	gasPrice, err := p.Backend.SuggestGasPrice(ctx)
+	if err != nil {
+		return err
+	}
+
+	opts.GasPrice, opts.GasFeeCap, opts.GasTipCap = gasPrice, nil, nil
+	return nil
+}
+
+// DynamicPricer pays head's base fee plus node's suggested tip, fee cap leaves room for the base fee to double
+type DynamicPricer struct {
+	Backend Backend
+}
+
+func (p *DynamicPricer) Price(ctx context.Context, opts *bind.TransactOpts) error {
+	baseFee, err := headBaseFee(ctx, p.Backend)
+	if err != nil {
+		return err
+	}
+
+	tip, err := p.Backend.SuggestGasTipCap(ctx)
+	if err != nil {
+		return err
+	}
+
+	setDynamicFee(opts, baseFee, tip)
+	return nil
+}
+
+// PercentilePricer pays next block's base fee plus average Percentile tip of the last Blocks blocks
+type PercentilePricer struct {
+	Backend    Backend
+	Percentile float64 // 0 - 100
+	Blocks     uint64
+}
+
+func (p *PercentilePricer) Price(ctx context.Context, opts *bind.TransactOpts) error {
+	backend, ok := p.Backend.(feeHistoryBackend)
+	if !ok {
+		return FeeHistoryUnsupportedError
+	}
+
+	history, err := backend.FeeHistory(ctx, p.Blocks, nil, []float64{p.Percentile})
+	if err != nil {
+		return err
+	}
+	// base fees go one block past the requested range - that's the next block's
+	if len(history.BaseFee) == 0 || history.BaseFee[len(history.BaseFee)-1].Sign() == 0 {
+		return NotEIP1559Error
+	}
+	baseFee := history.BaseFee[len(history.BaseFee)-1]
+
+	tip := new(big.Int)
+	for _, rewards := range history.Reward {
+		tip.Add(tip, rewards[0])
+	}
+	if len(history.Reward) > 0 {
+		tip.Div(tip, big.NewInt(int64(len(history.Reward))))
+	}
+
+	setDynamicFee(opts, baseFee, tip)
+	return nil
+}
+
+// FixedPricer pays configured values - GasPrice on any chain or GasFeeCap and GasTipCap on EIP-1559 chains
+type FixedPricer struct {
+	GasPrice  *big.Int
+	GasFeeCap *big.Int
+	GasTipCap *big.Int
+}
+
+func (p *FixedPricer) Price(ctx context.Context, opts *bind.TransactOpts) error {
+	opts.GasPrice, opts.GasFeeCap, opts.GasTipCap = p.GasPrice, p.GasFeeCap, p.GasTipCap
+	return nil
+}
+
+// AutoPricer detects the chain on every transaction - dynamic fees once it has a base fee, legacy before
+type AutoPricer struct {
+	Backend Backend
+}
+
+func (p *AutoPricer) Price(ctx context.Context, opts *bind.TransactOpts) error {
+	if _, err := headBaseFee(ctx, p.Backend); err == NotEIP1559Error {
+		return (&LegacyPricer{p.Backend}).Price(ctx, opts)
+	}
+
+	return (&DynamicPricer{p.Backend}).Price(ctx, opts)
+}
+
+// noDeferKey context key of sends which must not wait for fees to drop, see withoutDefer
+type noDeferKey struct{}
+
+// withoutDefer makes CeilingPricer refuse instead of deferring, for sends holding up others like a batch's
+func withoutDefer(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noDeferKey{}, true)
+}
+
+// CeilingPricer keeps Pricer's fees at or below Ceiling wei per gas. Fee caps above it are lowered
+// to it while base fee plus tip still fits, otherwise the send is refused or deferred until it fits.
+// Sends with a withoutDefer context are always refused.
+type CeilingPricer struct {
+	Pricer  GasPricer
+	Backend Backend
+	Ceiling *big.Int
+	Defer   bool          // wait for fees to drop instead of refusing
+	Timeout time.Duration // how long to wait when deferring
+}
+
+func (p *CeilingPricer) Price(ctx context.Context, opts *bind.TransactOpts) error {
+	deadline := time.Now().Add(p.Timeout)
+
+	for {
+		err := p.price(ctx, opts)
+		if err != GasPriceTooHighError || !p.Defer || ctx.Value(noDeferKey{}) != nil || time.Now().Add(deferInterval).After(deadline) {
+			return err
+		}
+
+		log.Printf("Gas: fees above ceiling %s, retrying in %s", p.Ceiling, deferInterval)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(deferInterval):
+		}
+	}
+}
+
+// price single attempt of Price
+func (p *CeilingPricer) price(ctx context.Context, opts *bind.TransactOpts) error {
+	if err := p.Pricer.Price(ctx, opts); err != nil {
+		return err
+	}
+
+	if opts.GasPrice != nil {
+		if opts.GasPrice.Cmp(p.Ceiling) > 0 {
+			return GasPriceTooHighError
+		}
+		return nil
+	}
+
+	if opts.GasFeeCap == nil || opts.GasFeeCap.Cmp(p.Ceiling) <= 0 {
+		return nil
+	}
+
+	baseFee, err := headBaseFee(ctx, p.Backend)
+	if err != nil {
+		return err
+	}
+	if new(big.Int).Add(baseFee, opts.GasTipCap).Cmp(p.Ceiling) > 0 {
+		return GasPriceTooHighError
+	}
+
+	opts.GasFeeCap = new(big.Int).Set(p.Ceiling)
+	return nil
+}
+
+// waitForFees holds back until the ceiling lets sends through, at most Timeout. Deferred
+// batches wait here once, their sends are refused instead of deferring one by one.
+func (p *CeilingPricer) waitForFees(ctx context.Context, opts bind.TransactOpts) error {
+	if !p.Defer {
+		return nil
+	}
+
+	return p.Price(ctx, &opts)
+}
+
+// GasLimits derives transaction's gas limit from its estimate
+type GasLimits struct {
+	Multiplier float64           // safety margin on top of the estimate
+	Caps       map[string]uint64 // max gas limit per operation, no cap when missing
+}
+
+// Limit estimate times Multiplier, lowered to operation's cap. Estimates above the cap are refused.
+func (l *GasLimits) Limit(operation string, estimate uint64) (uint64, error) {
+	limit := uint64(float64(estimate) * l.Multiplier)
+
+	if cap, ok := l.Caps[operation]; ok {
+		if estimate > cap {
+			return 0, GasCapError
+		}
+		if limit > cap {
+			limit = cap
+		}
+	}
+
+	return limit, nil
+}
+
+// headBaseFee base fee of the latest block, NotEIP1559Error before London
+func headBaseFee(ctx context.Context, backend Backend) (*big.Int, error) {
+	head, err := backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if head.BaseFee == nil {
+		return nil, NotEIP1559Error
+	}
+
+	return head.BaseFee, nil
+}
+
+// setDynamicFee tip plus twice the base fee as the cap - stays includable for 6 full blocks in a row
+func setDynamicFee(opts *bind.TransactOpts, baseFee, tip *big.Int) {
+	feeCap := new(big.Int).Mul(baseFee, big.NewInt(2))
+	feeCap.Add(feeCap, tip)
+
+	opts.GasPrice, opts.GasFeeCap, opts.GasTipCap = nil, feeCap, tip
+}
+
+// gasPricer configured strategy, wrapped in CeilingPricer when there is a ceiling
+func gasPricer(backend Backend, cfg *gasConfig) (GasPricer, error) {
+	var pricer GasPricer
+
+	switch cfg.Strategy {
+	case "", GasAuto:
+		pricer = &AutoPricer{backend}
+	case GasLegacy:
+		pricer = &LegacyPricer{backend}
+	case GasEIP1559:
+		pricer = &DynamicPricer{backend}
+	case GasPercentile:
+		pricer = &PercentilePricer{backend, cfg.percentile(), cfg.blocks()}
+	case GasFixed:
+		gasPrice, err := cfg.wei(cfg.GasPrice)
+		if err != nil {
+			return nil, err
+		}
+		feeCap, err := cfg.wei(cfg.MaxFeePerGas)
+		if err != nil {
+			return nil, err
+		}
+		tip, err := cfg.wei(cfg.MaxPriorityFeePerGas)
+		if err != nil {
+			return nil, err
+		}
+		// legacy and dynamic fee fields don't go together in a transaction
+		legacy, dynamic := gasPrice != nil, feeCap != nil || tip != nil
+		if legacy == dynamic || (dynamic && (feeCap == nil || tip == nil)) {
+			return nil, FixedGasError
+		}
+		pricer = &FixedPricer{gasPrice, feeCap, tip}
+	default:
+		return nil, UnknownGasStrategyError
+	}
+
+	ceiling, err := cfg.wei(cfg.Ceiling)
+	if err != nil {
+		return nil, err
+	}
+	if ceiling == nil {
+		return pricer, nil
+	}
+
+	return &CeilingPricer{pricer, backend, ceiling, cfg.OnCeiling == "defer", cfg.deferTimeout()}, nil
+}
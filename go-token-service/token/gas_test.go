@@ -0,0 +1,193 @@
+package token_test
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"ERC20Whitelistable/go-token-service/token"
+	"ERC20Whitelistable/go-token-service/tokentest"
+)
+
+func TestAutoPricerSendsDynamicFee(t *testing.T) {
+	env := tokentest.New(t)
+
+	output, err := env.Token.WhitelistAddress(&token.WhitelistInput{Address: tokentest.NewAddress(t)})
+	if err != nil {
+		t.Fatalf("WhitelistAddress failed: %v", err)
+	}
+
+	tx, _, err := env.Chain.TransactionByHash(context.Background(), common.HexToHash(output.TransactionHash))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Type() != types.DynamicFeeTxType {
+		t.Errorf("expected dynamic fee transaction, got type %d", tx.Type())
+	}
+
+	env.Token.Tracker.Poll()
+	job, _ := env.Token.GetJob(output.JobID)
+	if job.EffectiveGasPrice == "" || job.EffectiveGasPrice == tx.GasFeeCap().String() {
+		t.Errorf("expected base fee plus tip as effective gas price, got %+v", job)
+	}
+}
+
+func TestPercentilePricer(t *testing.T) {
+	env := tokentest.New(t)
+	env.Whitelist(t, tokentest.NewAddress(t))
+
+	opts := &bind.TransactOpts{}
+	pricer := &token.PercentilePricer{Backend: env.Chain, Percentile: 50, Blocks: 10}
+	if err := pricer.Price(context.Background(), opts); err != nil {
+		t.Fatal(err)
+	}
+	if opts.GasPrice != nil || opts.GasTipCap == nil || opts.GasFeeCap.Cmp(opts.GasTipCap) <= 0 {
+		t.Errorf("unexpected fees: feeCap %v tip %v gasPrice %v", opts.GasFeeCap, opts.GasTipCap, opts.GasPrice)
+	}
+}
+
+func TestCeilingRefuses(t *testing.T) {
+	env := tokentest.New(t)
+	env.Token.Gas = &token.CeilingPricer{
+		Pricer:  &token.FixedPricer{GasPrice: big.NewInt(2e9)},
+		Backend: env.Chain,
+		Ceiling: big.NewInt(1e9),
+	}
+
+	output, err := env.Token.WhitelistAddress(&token.WhitelistInput{Address: tokentest.NewAddress(t)})
+	if err != token.GasPriceTooHighError {
+		t.Fatalf("expected GasPriceTooHighError, got %v", err)
+	}
+	if output.State != token.JobFailed {
+		t.Errorf("unexpected output: %+v", output)
+	}
+
+	// refused send doesn't hold a nonce
+	if state := env.Token.Nonces.State(); len(state.Reserved) != 0 || len(state.Released) != 0 {
+		t.Errorf("unexpected nonce state: %+v", state)
+	}
+}
+
+// risingPricer legacy gas price which jumps after the first calls
+type risingPricer struct {
+	calls int
+	sync.Mutex
+}
+
+func (p *risingPricer) Price(ctx context.Context, opts *bind.TransactOpts) error {
+	p.Lock()
+	defer p.Unlock()
+
+	p.calls++
+	opts.GasPrice = big.NewInt(100e9)
+	if p.calls > 2 {
+		opts.GasPrice = big.NewInt(300e9)
+	}
+	return nil
+}
+
+func TestCeilingDeferDoesntHoldUpBatch(t *testing.T) {
+	env := tokentest.New(t)
+	env.Token.Gas = &token.CeilingPricer{
+		Pricer:  &risingPricer{},
+		Backend: env.Chain,
+		Ceiling: big.NewInt(200e9),
+		Defer:   true,
+		Timeout: time.Minute,
+	}
+
+	// fees fit for the wait before sending and the first send, the second one is refused right away
+	start := time.Now()
+	output, err := env.Token.WhitelistMultiple(&token.WhitelistMultiInput{Addresses: []token.WhitelistInput{
+		{Address: tokentest.NewAddress(t)},
+		{Address: tokentest.NewAddress(t)},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("batch waited %s", elapsed)
+	}
+
+	if tx := output.Transactions[0]; tx.Error != nil {
+		t.Errorf("unexpected output: %+v", tx)
+	}
+	if tx := output.Transactions[1]; tx.Error == nil || tx.Error.Code != token.CodeGasPriceTooHigh {
+		t.Errorf("expected %s, got %+v", token.CodeGasPriceTooHigh, tx)
+	}
+}
+
+func TestCeilingLowersFeeCap(t *testing.T) {
+	env := tokentest.New(t)
+
+	head, err := env.Chain.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// base fee plus tip fits, twice the base fee doesn't
+	ceiling := new(big.Int).Add(head.BaseFee, big.NewInt(1e6))
+	pricer := &token.CeilingPricer{
+		Pricer:  &token.FixedPricer{GasFeeCap: new(big.Int).Mul(head.BaseFee, big.NewInt(2)), GasTipCap: big.NewInt(1)},
+		Backend: env.Chain,
+		Ceiling: ceiling,
+	}
+
+	opts := &bind.TransactOpts{}
+	if err := pricer.Price(context.Background(), opts); err != nil {
+		t.Fatal(err)
+	}
+	if opts.GasFeeCap.Cmp(ceiling) != 0 {
+		t.Errorf("expected fee cap %s, got %s", ceiling, opts.GasFeeCap)
+	}
+}
+
+func TestGasLimitFromEstimate(t *testing.T) {
+	env := tokentest.New(t)
+
+	output, err := env.Token.WhitelistAddress(&token.WhitelistInput{Address: tokentest.NewAddress(t)})
+	if err != nil {
+		t.Fatalf("WhitelistAddress failed: %v", err)
+	}
+	if output.EstimatedGas == 0 {
+		t.Errorf("expected estimated gas, got %+v", output)
+	}
+
+	env.Token.Tracker.Poll()
+	job, _ := env.Token.GetJob(output.JobID)
+	if expected := uint64(float64(job.EstimatedGas) * 1.2); job.GasLimit != expected {
+		t.Errorf("expected gas limit %d, got %+v", expected, job)
+	}
+	if job.GasUsed == 0 || job.GasUsed > job.GasLimit {
+		t.Errorf("unexpected gas used: %+v", job)
+	}
+}
+
+func TestGasLimits(t *testing.T) {
+	limits := &token.GasLimits{Multiplier: 1.5, Caps: map[string]uint64{"mint": 120000}}
+
+	tests := []struct {
+		operation string
+		estimate  uint64
+		limit     uint64
+		err       error
+	}{
+		{"grantRole", 100000, 150000, nil},
+		{"mint", 60000, 90000, nil},
+		{"mint", 100000, 120000, nil}, // lowered to the cap
+		{"mint", 130000, 0, token.GasCapError},
+	}
+
+	for _, test := range tests {
+		limit, err := limits.Limit(test.operation, test.estimate)
+		if limit != test.limit || err != test.err {
+			t.Errorf("%s %d: expected %d, %v got %d, %v", test.operation, test.estimate, test.limit, test.err, limit, err)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package token
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	IdempotencyKeyReusedError = errors.New("Idempotency Key Reused With Different Parameters")
+)
+
+// fingerprint of an operation's parameters, tells a retried request from a different one under the same key
+func fingerprint(operation, address string, params ...string) string {
+	parts := append([]string{operation, strings.ToLower(address)}, params...)
+	return crypto.Keccak256Hash([]byte(strings.Join(parts, "\x00"))).Hex()
+}
+
+// NewKeyedJob queues a job for operation on address under idempotency key, an empty key is NewJob.
+// A known key hands back its job with replayed set - unless the job failed before anything was signed,
+// then it's retried. A known key with different parameters fails with IdempotencyKeyReusedError
+// and an unkeyed job to report the refusal with.
+func (t *Tracker) NewKeyedJob(key, operation, address string, params ...string) (job *Job, replayed bool, err error) {
+	if key == "" {
+		return t.NewJob(operation, address), false, nil
+	}
+	sum := fingerprint(operation, address, params...)
+
+	t.Lock()
+	defer t.Unlock()
+
+	if job, ok := t.keys[key]; ok {
+		if job.Fingerprint != sum {
+			return t.newJob(operation, address), false, IdempotencyKeyReusedError
+		}
+		if job.State != JobFailed || job.tx != nil {
+			return job, true, nil
+		}
+	}
+
+	job = t.newJob(operation, address)
+	job.IdempotencyKey, job.Fingerprint = key, sum
+	t.keys[key] = job
+	t.record(job)
+
+	return job, false, nil
+}
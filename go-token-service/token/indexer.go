@@ -0,0 +1,288 @@
+package token
+
+import (
+	"context"
+	"log"
+	"math/big"
+	"sort"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"ERC20Whitelistable/go-token-service/contracts"
+)
+
+// defaultIndexerChunkSize blocks per log query, most providers accept it
+const defaultIndexerChunkSize = 2000
+
+// Indexer stores Transfer and role events of the contract. It backfills from FromBlock in chunks
+// of ChunkSize blocks, then follows new blocks. Blocks orphaned by a reorg are rolled back.
+type Indexer struct {
+	FromBlock uint64 // contract's deployment block
+	ChunkSize uint64 // blocks per log query
+
+	backend   Backend
+	address   common.Address
+	filterer  *token.TokenFilterer
+	roleNames map[[32]byte]string
+	store     EventStore
+	interval  time.Duration
+	stop      chan struct{}
+	done      chan struct{} // closed once background indexing ended, nil until Start
+}
+
+// NewIndexer indexes the contract at address into store, polling every interval once caught up.
+// Role events are named after roles - the token's Roles.
+func NewIndexer(backend Backend, address common.Address, roles map[string][32]byte, store EventStore, fromBlock, chunkSize uint64, interval time.Duration) (*Indexer, error) {
+	filterer, err := token.NewTokenFilterer(address, backend)
+	if err != nil {
+		return nil, err
+	}
+
+	roleNames := map[[32]byte]string{}
+	for name, role := range roles {
+		roleNames[role] = name
+	}
+
+	return &Indexer{fromBlock, chunkSize, backend, address, filterer, roleNames, store, interval, make(chan struct{}), nil}, nil
+}
+
+// roleName name of a known role, hex otherwise
+func (idx *Indexer) roleName(role [32]byte) string {
+	if name, ok := idx.roleNames[role]; ok {
+		return name
+	}
+
+	return common.Hash(role).Hex()
+}
+
+// Start indexes in the background until Stop
+func (idx *Indexer) Start() {
+	idx.done = make(chan struct{})
+
+	go func() {
+		defer close(idx.done)
+
+		for {
+			caughtUp, err := idx.Step()
+			if err != nil {
+				log.Println("Indexer: ", err)
+			}
+
+			// backfill goes on right away
+			if caughtUp || err != nil {
+				select {
+				case <-idx.stop:
+					return
+				case <-time.After(idx.interval):
+				}
+			}
+
+			select {
+			case <-idx.stop:
+				return
+			default:
+			}
+		}
+	}()
+}
+
+// Stop ends background indexing and closes the store
+func (idx *Indexer) Stop() {
+	close(idx.stop)
+	if idx.done != nil {
+		<-idx.done
+	}
+	idx.store.Close()
+}
+
+// Cursor last indexed block, nil before the first one
+func (idx *Indexer) Cursor() (*BlockRef, error) {
+	return idx.store.Cursor()
+}
+
+// Events stored events passing the filter
+func (idx *Indexer) Events(filter *EventFilter) ([]Event, error) {
+	return idx.store.Events(filter)
+}
+
+// Step indexes the next chunk of blocks or rolls back a reorg, caughtUp once the cursor is at the head
+func (idx *Indexer) Step() (caughtUp bool, err error) {
+	ctx := context.Background()
+
+	head, err := idx.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+
+	start := idx.FromBlock
+	cursor, err := idx.store.Cursor()
+	if err != nil {
+		return false, err
+	}
+	if cursor != nil {
+		// cursor's block left the chain - roll back to the last block still on it
+		header, err := idx.backend.HeaderByNumber(ctx, new(big.Int).SetUint64(cursor.Number))
+		if err != nil {
+			return false, err
+		}
+		if header.Hash().Hex() != cursor.Hash {
+			return false, idx.rollback()
+		}
+		start = cursor.Number + 1
+	}
+
+	if start > head.Number.Uint64() {
+		return true, nil
+	}
+	end := start + idx.ChunkSize - 1
+	if end > head.Number.Uint64() {
+		end = head.Number.Uint64()
+	}
+
+	endHeader, err := idx.backend.HeaderByNumber(ctx, new(big.Int).SetUint64(end))
+	if err != nil {
+		return false, err
+	}
+
+	events, err := idx.filter(ctx, start, end)
+	if err != nil {
+		return false, err
+	}
+
+	// a reorg while filtering - the next step tries again
+	check, err := idx.backend.HeaderByNumber(ctx, new(big.Int).SetUint64(end))
+	if err != nil || check.Hash() != endHeader.Hash() {
+		return false, err
+	}
+
+	if err := idx.store.Append(events, BlockRef{end, endHeader.Hash().Hex()}); err != nil {
+		return false, err
+	}
+
+	return end == head.Number.Uint64(), nil
+}
+
+// rollback drops events above the newest recent block which is still on the chain
+func (idx *Indexer) rollback() error {
+	recent, err := idx.store.Recent()
+	if err != nil {
+		return err
+	}
+
+	for index := len(recent) - 1; index >= 0; index-- {
+		header, err := idx.backend.HeaderByNumber(context.Background(), new(big.Int).SetUint64(recent[index].Number))
+		if err != nil {
+			return err
+		}
+		if header.Hash().Hex() == recent[index].Hash {
+			log.Printf("Indexer: reorg, rolled back to block %d", recent[index].Number)
+			return idx.store.Rollback(&recent[index])
+		}
+	}
+
+	// forked off before every block we know - index again from the start
+	log.Println("Indexer: reorg deeper than known blocks, indexing again")
+	return idx.store.Rollback(nil)
+}
+
+// indexedEvents names of the event kinds the indexer stores
+var indexedEvents = []string{EventTransfer, EventRoleGranted, EventRoleRevoked, EventRoleAdminChanged}
+
+// filter decoded events of all indexed kinds within blocks start to end, in chain order.
+// A single log query asks for all kinds at once.
+func (idx *Indexer) filter(ctx context.Context, start, end uint64) ([]Event, error) {
+	parsed, err := tokenABI()
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[common.Hash]string{}
+	topics := []common.Hash{}
+	for _, name := range indexedEvents {
+		names[parsed.Events[name].ID] = name
+		topics = append(topics, parsed.Events[name].ID)
+	}
+
+	logs, err := idx.backend.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(start),
+		ToBlock:   new(big.Int).SetUint64(end),
+		Addresses: []common.Address{idx.address},
+		Topics:    [][]common.Hash{topics},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events := []Event{}
+	for _, raw := range logs {
+		if raw.Removed || len(raw.Topics) == 0 {
+			continue
+		}
+		name, ok := names[raw.Topics[0]]
+		if !ok {
+			continue
+		}
+
+		event, err := idx.decode(name, raw)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].BlockNumber != events[j].BlockNumber {
+			return events[i].BlockNumber < events[j].BlockNumber
+		}
+		return events[i].LogIndex < events[j].LogIndex
+	})
+	return events, nil
+}
+
+// decode event named name from its log through the bound filterer
+func (idx *Indexer) decode(name string, raw types.Log) (Event, error) {
+	event := newEvent(name, raw)
+
+	switch name {
+	case EventTransfer:
+		e, err := idx.filterer.ParseTransfer(raw)
+		if err != nil {
+			return event, err
+		}
+		event.From, event.To, event.Value = e.From.Hex(), e.To.Hex(), e.Value.String()
+	case EventRoleGranted:
+		e, err := idx.filterer.ParseRoleGranted(raw)
+		if err != nil {
+			return event, err
+		}
+		event.Role, event.Account, event.Sender = idx.roleName(e.Role), e.Account.Hex(), e.Sender.Hex()
+	case EventRoleRevoked:
+		e, err := idx.filterer.ParseRoleRevoked(raw)
+		if err != nil {
+			return event, err
+		}
+		event.Role, event.Account, event.Sender = idx.roleName(e.Role), e.Account.Hex(), e.Sender.Hex()
+	case EventRoleAdminChanged:
+		e, err := idx.filterer.ParseRoleAdminChanged(raw)
+		if err != nil {
+			return event, err
+		}
+		event.Role, event.PreviousAdminRole, event.NewAdminRole = idx.roleName(e.Role), idx.roleName(e.PreviousAdminRole), idx.roleName(e.NewAdminRole)
+	}
+
+	return event, nil
+}
+
+// newEvent event named name from its log
+func newEvent(name string, raw types.Log) Event {
+	return Event{
+		Name:        name,
+		BlockNumber: raw.BlockNumber,
+		BlockHash:   raw.BlockHash.Hex(),
+		TxHash:      raw.TxHash.Hex(),
+		LogIndex:    raw.Index,
+	}
+}
@@ -0,0 +1,162 @@
+package token_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"ERC20Whitelistable/go-token-service/devchain"
+	"ERC20Whitelistable/go-token-service/token"
+	"ERC20Whitelistable/go-token-service/tokentest"
+)
+
+// index steps the indexer until it's at the head
+func index(t *testing.T, idx *token.Indexer) {
+	t.Helper()
+
+	for i := 0; i < 100; i++ {
+		caughtUp, err := idx.Step()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if caughtUp {
+			return
+		}
+	}
+	t.Fatal("indexer didn't catch up")
+}
+
+func newIndexer(t *testing.T, env *tokentest.Env) *token.Indexer {
+	idx, err := token.NewIndexer(env.Chain, env.Chain.ContractAddress, env.Token.Roles(), token.NewMemoryEventStore(), 0, 2, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return idx
+}
+
+func TestIndexer(t *testing.T) {
+	env := tokentest.New(t)
+	address := tokentest.NewAddress(t)
+	env.Whitelist(t, address)
+	if _, err := env.Token.Mint(&token.MintInput{Address: address, Amount: "50"}); err != nil {
+		t.Fatal(err)
+	}
+
+	idx := newIndexer(t, env)
+	index(t, idx)
+
+	granted, _ := idx.Events(&token.EventFilter{Name: token.EventRoleGranted, Address: address})
+	if len(granted) != 1 || granted[0].Role != token.WhitelistedRoleName {
+		t.Errorf("unexpected events: %+v", granted)
+	}
+	transfers, _ := idx.Events(&token.EventFilter{Name: token.EventTransfer, Address: address})
+	if len(transfers) != 1 || transfers[0].From != (common.Address{}).Hex() || transfers[0].Value != "50" {
+		t.Errorf("unexpected events: %+v", transfers)
+	}
+
+	head, _ := env.Chain.HeaderByNumber(context.Background(), nil)
+	if cursor, _ := idx.Cursor(); cursor.Number != head.Number.Uint64() || cursor.Hash != head.Hash().Hex() {
+		t.Errorf("expected cursor at head %d, got %+v", head.Number, cursor)
+	}
+}
+
+func TestIndexerReorg(t *testing.T) {
+	env := tokentest.New(t)
+	address := tokentest.NewAddress(t)
+	env.Whitelist(t, address)
+	if _, err := env.Token.Mint(&token.MintInput{Address: address, Amount: "50"}); err != nil {
+		t.Fatal(err)
+	}
+
+	idx := newIndexer(t, env)
+	index(t, idx)
+
+	// the mint's block is replaced by a longer fork without it
+	head, _ := env.Chain.HeaderByNumber(context.Background(), nil)
+	if err := env.Chain.Fork(context.Background(), head.ParentHash); err != nil {
+		t.Fatal(err)
+	}
+	env.Chain.Commit()
+	env.Chain.Commit()
+
+	index(t, idx)
+
+	if transfers, _ := idx.Events(&token.EventFilter{Name: token.EventTransfer, Address: address}); len(transfers) != 0 {
+		t.Errorf("expected orphaned transfer to be rolled back, got %+v", transfers)
+	}
+	if granted, _ := idx.Events(&token.EventFilter{Name: token.EventRoleGranted, Address: address}); len(granted) != 1 {
+		t.Errorf("expected grant once, got %+v", granted)
+	}
+	newHead, _ := env.Chain.HeaderByNumber(context.Background(), nil)
+	if cursor, _ := idx.Cursor(); cursor.Hash != newHead.Hash().Hex() {
+		t.Errorf("expected cursor at new head %s, got %+v", newHead.Hash().Hex(), cursor)
+	}
+}
+
+func TestFileEventStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	store, err := token.OpenFileEventStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Append([]token.Event{{Name: token.EventTransfer, BlockNumber: 1}}, token.BlockRef{Number: 2, Hash: "0x02"})
+	store.Append([]token.Event{{Name: token.EventTransfer, BlockNumber: 3}}, token.BlockRef{Number: 4, Hash: "0x04"})
+	store.Rollback(&token.BlockRef{Number: 2, Hash: "0x02"})
+	store.Close()
+
+	store, err = token.OpenFileEventStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	events, _ := store.Events(&token.EventFilter{})
+	if cursor, _ := store.Cursor(); len(events) != 1 || events[0].BlockNumber != 1 || cursor.Number != 2 {
+		t.Errorf("unexpected events %+v at cursor %+v", events, cursor)
+	}
+}
+
+// logQueriesChain counts log queries
+type logQueriesChain struct {
+	*devchain.Chain
+	queries int
+}
+
+func (c *logQueriesChain) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	c.queries++
+	return c.Chain.FilterLogs(ctx, query)
+}
+
+func TestIndexerSingleLogQuery(t *testing.T) {
+	env := tokentest.New(t)
+	address := tokentest.NewAddress(t)
+	env.Whitelist(t, address)
+	if _, err := env.Token.Mint(&token.MintInput{Address: address, Amount: "50"}); err != nil {
+		t.Fatal(err)
+	}
+
+	backend := &logQueriesChain{Chain: env.Chain}
+	idx, err := token.NewIndexer(backend, env.Chain.ContractAddress, env.Token.Roles(), token.NewMemoryEventStore(), 0, 1000, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if caughtUp, err := idx.Step(); err != nil || !caughtUp {
+		t.Fatalf("expected a single step to catch up, got %v, %v", caughtUp, err)
+	}
+
+	// every kind comes from the same query
+	if backend.queries != 1 {
+		t.Errorf("expected 1 log query, got %d", backend.queries)
+	}
+	events, _ := idx.Events(&token.EventFilter{Address: address})
+	if len(events) != 2 || events[0].Name != token.EventRoleGranted || events[1].Name != token.EventTransfer || events[1].Value != "50" {
+		t.Errorf("unexpected events: %+v", events)
+	}
+}
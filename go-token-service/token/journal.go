@@ -0,0 +1,350 @@
+package token
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var (
+	InterruptedError = errors.New("Interrupted Before Sending")
+)
+
+// Journal stores jobs and batch jobs so a restart doesn't lose track of sent transactions.
+// Every state change is saved, signed transactions before they are broadcast.
+type Journal interface {
+	Save(entry *JournalEntry) error  // latest entry of a job replaces the previous ones
+	Load() ([]*JournalEntry, error)  // latest entry of every job
+	SaveBatch(batch *BatchJob) error // latest snapshot of a batch job replaces the previous ones
+	LoadBatches() ([]*BatchJob, error)
+	Close() error
+}
+
+// JournalEntry job with its signed transaction
+type JournalEntry struct {
+	Job
+	RawTx string `json:"rawTx,omitempty"` // signed transaction, hex of its binary encoding
+	Final bool   `json:"final"`
+}
+
+// newJournalEntry snapshot of the job for the journal
+func newJournalEntry(job *Job) (*JournalEntry, error) {
+	entry := &JournalEntry{Job: *job, Final: job.final}
+	entry.History = append([]Transition{}, job.History...)
+
+	if job.tx != nil {
+		raw, err := job.tx.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		entry.RawTx = hexutil.Encode(raw)
+	}
+
+	return entry, nil
+}
+
+// job restores the job with its transaction
+func (e *JournalEntry) job() (*Job, error) {
+	job := e.Job
+	job.final = e.Final
+
+	if e.RawTx != "" {
+		raw, err := hexutil.Decode(e.RawTx)
+		if err != nil {
+			return nil, err
+		}
+
+		job.tx = new(types.Transaction)
+		if err := job.tx.UnmarshalBinary(raw); err != nil {
+			return nil, err
+		}
+
+		// the transaction is the source of truth
+		nonce := job.tx.Nonce()
+		job.Nonce, job.TransactionHash = &nonce, job.tx.Hash().Hex()
+	}
+
+	return &job, nil
+}
+
+// expired final entry past trackerRetention
+func (e *JournalEntry) expired() bool {
+	return e.Final && len(e.History) > 0 && time.Since(e.History[len(e.History)-1].At) > trackerRetention
+}
+
+// journalLine line of FileJournal - a job's entry or a batch job's snapshot
+type journalLine struct {
+	*JournalEntry
+	Batch *BatchJob `json:"batch,omitempty"`
+}
+
+// MemoryJournal keeps entries only as long as the process runs
+type MemoryJournal struct {
+	entries    map[string]*JournalEntry
+	order      []string // job ids in order of their first entry
+	batches    map[string]*BatchJob
+	batchOrder []string
+
+	sync.Mutex
+}
+
+// NewMemoryJournal empty journal
+func NewMemoryJournal() *MemoryJournal {
+	return &MemoryJournal{entries: map[string]*JournalEntry{}, order: []string{}, batches: map[string]*BatchJob{}, batchOrder: []string{}}
+}
+
+// Save replaces job's entry
+func (j *MemoryJournal) Save(entry *JournalEntry) error {
+	j.Lock()
+	defer j.Unlock()
+
+	if _, ok := j.entries[entry.ID]; !ok {
+		j.order = append(j.order, entry.ID)
+	}
+	j.entries[entry.ID] = entry
+	return nil
+}
+
+// Load latest entries in order of their jobs
+func (j *MemoryJournal) Load() ([]*JournalEntry, error) {
+	j.Lock()
+	defer j.Unlock()
+
+	entries := []*JournalEntry{}
+	for _, id := range j.order {
+		entries = append(entries, j.entries[id])
+	}
+	return entries, nil
+}
+
+// SaveBatch keeps the batch job's snapshot
+func (j *MemoryJournal) SaveBatch(batch *BatchJob) error {
+	j.Lock()
+	defer j.Unlock()
+
+	j.saveBatch(batch)
+	return nil
+}
+
+// saveBatch callers hold the lock
+func (j *MemoryJournal) saveBatch(batch *BatchJob) {
+	if _, ok := j.batches[batch.ID]; !ok {
+		j.batchOrder = append(j.batchOrder, batch.ID)
+	}
+	j.batches[batch.ID] = batch
+}
+
+// LoadBatches batch jobs in order of their first snapshot
+func (j *MemoryJournal) LoadBatches() ([]*BatchJob, error) {
+	j.Lock()
+	defer j.Unlock()
+
+	batches := []*BatchJob{}
+	for _, id := range j.batchOrder {
+		batches = append(batches, j.batches[id])
+	}
+	return batches, nil
+}
+
+// Close no-op
+func (j *MemoryJournal) Close() error {
+	return nil
+}
+
+// FileJournal appends entries and batch job snapshots as JSON lines, synced to disk before Save returns.
+// Opening compacts the file to the latest entry of every job and batch job, expired ones are dropped -
+// unless a broken line was skipped, see openJSONLines.
+type FileJournal struct {
+	lines *jsonLines
+
+	*MemoryJournal
+}
+
+// OpenFileJournal opens or creates the journal at path
+func OpenFileJournal(path string) (*FileJournal, error) {
+	memory := NewMemoryJournal()
+	intact, end, err := readJSONLines(path, func(raw json.RawMessage) error {
+		var line journalLine
+		if err := json.Unmarshal(raw, &line); err != nil {
+			return err
+		}
+		if line.Batch != nil {
+			return memory.SaveBatch(line.Batch)
+		}
+		if line.JournalEntry == nil {
+			return nil
+		}
+		return memory.Save(line.JournalEntry)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries, _ := memory.Load()
+	kept, records := NewMemoryJournal(), []interface{}{}
+	for _, entry := range entries {
+		if entry.expired() {
+			continue
+		}
+		kept.Save(entry)
+		records = append(records, entry)
+	}
+	batches, _ := memory.LoadBatches()
+	for _, batch := range batches {
+		if batch.expired() {
+			continue
+		}
+		kept.SaveBatch(batch)
+		records = append(records, &journalLine{Batch: batch})
+	}
+
+	lines, err := openJSONLines(path, intact, end, records)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileJournal{lines, kept}, nil
+}
+
+// Save appends the entry
+func (j *FileJournal) Save(entry *JournalEntry) error {
+	j.Lock()
+	defer j.Unlock()
+
+	if err := j.lines.append(entry); err != nil {
+		return err
+	}
+
+	if _, ok := j.entries[entry.ID]; !ok {
+		j.order = append(j.order, entry.ID)
+	}
+	j.entries[entry.ID] = entry
+	return nil
+}
+
+// SaveBatch appends the batch job's snapshot
+func (j *FileJournal) SaveBatch(batch *BatchJob) error {
+	j.Lock()
+	defer j.Unlock()
+
+	if err := j.lines.append(&journalLine{Batch: batch}); err != nil {
+		return err
+	}
+
+	j.saveBatch(batch)
+	return nil
+}
+
+// Close closes the file
+func (j *FileJournal) Close() error {
+	return j.lines.Close()
+}
+
+// record saves job's current state to the journal
+func (t *Tracker) record(job *Job) error {
+	entry, err := newJournalEntry(job)
+	if err != nil {
+		return err
+	}
+
+	return t.journal.Save(entry)
+}
+
+// Reconcile restores journaled jobs and settles the ones a restart interrupted against the chain:
+// queued jobs never sent fail, signed transactions the node doesn't know are broadcast again
+// in nonce order - with the same nonce, so nothing is sent twice. Runs before nonces are handed out.
+func (t *Tracker) Reconcile() error {
+	entries, err := t.journal.Load()
+	if err != nil {
+		return err
+	}
+
+	// nonces below it are used on chain
+	accountNonce, err := t.backend.NonceAt(context.Background(), t.account, nil)
+	if err != nil {
+		return err
+	}
+
+	signed := []*Job{}
+	t.Lock()
+	for _, entry := range entries {
+		if entry.expired() {
+			continue
+		}
+
+		job, err := entry.job()
+		if err != nil {
+			log.Printf("Journal: can't restore job %s: %v", entry.ID, err)
+			continue
+		}
+		t.jobs[job.ID] = job
+		if job.tx != nil {
+			t.hashes[job.tx.Hash()] = job
+		}
+		if job.IdempotencyKey != "" {
+			t.keys[job.IdempotencyKey] = job
+		}
+
+		switch {
+		case job.State == JobQueued:
+			job.Error, job.ErrorCode, job.final = InterruptedError.Error(), CodeInterrupted, true
+			t.transition(job, JobFailed, job.Error)
+		case job.State == JobSigned && job.tx != nil:
+			signed = append(signed, job)
+		}
+	}
+	t.Unlock()
+
+	sort.Slice(signed, func(i, j int) bool { return signed[i].tx.Nonce() < signed[j].tx.Nonce() })
+	for _, job := range signed {
+		t.resend(job, accountNonce)
+	}
+
+	return nil
+}
+
+// resend broadcasts signed transaction of the job unless the node has it or its nonce is used
+func (t *Tracker) resend(job *Job, accountNonce uint64) {
+	_, _, err := t.backend.TransactionByHash(context.Background(), job.tx.Hash())
+	if err == nil {
+		t.Lock()
+		t.transition(job, JobBroadcast, "found after restart")
+		t.Unlock()
+		return
+	}
+	if err != ethereum.NotFound {
+		// left signed - Poll doesn't watch it, GET /tx/{id} still reports it
+		log.Printf("Journal: can't look up job %s: %v", job.ID, err)
+		return
+	}
+
+	if job.tx.Nonce() < accountNonce {
+		t.Lock()
+		job.final = true
+		t.transition(job, JobBroadcast, "")
+		t.transition(job, JobReplaced, "nonce used by another transaction")
+		t.Unlock()
+		return
+	}
+
+	err = t.backend.SendTransaction(context.Background(), job.tx)
+
+	t.Lock()
+	defer t.Unlock()
+
+	// already known or waiting for a lower nonce - either way the node has it
+	if err != nil && !isNonceError(err) {
+		job.Error, job.ErrorCode, job.final = err.Error(), ErrorCode(err), true
+		t.transition(job, JobFailed, err.Error())
+		return
+	}
+	t.transition(job, JobBroadcast, "sent again after restart")
+}
@@ -0,0 +1,240 @@
+package token_test
+
+import (
+	"context"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"ERC20Whitelistable/go-token-service/token"
+	"ERC20Whitelistable/go-token-service/tokentest"
+)
+
+func TestFileJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+
+	journal, err := token.OpenFileJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range []*token.JournalEntry{
+		{Job: token.Job{ID: "first", State: token.JobQueued}},
+		{Job: token.Job{ID: "second", State: token.JobQueued}},
+		{Job: token.Job{ID: "first", State: token.JobSigned}, RawTx: "0x01"},
+	} {
+		if err := journal.Save(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	journal.Close()
+
+	// a crash cut the last line
+	file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	file.WriteString(`{"id": "third", "sta`)
+	file.Close()
+
+	journal, err = token.OpenFileJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer journal.Close()
+
+	entries, err := journal.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].ID != "first" || entries[0].State != token.JobSigned || entries[0].RawTx != "0x01" || entries[1].ID != "second" {
+		t.Errorf("unexpected entries: %+v", entries)
+	}
+}
+
+func TestFileJournalBrokenLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+
+	journal, err := token.OpenFileJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"first", "second", "third"} {
+		if err := journal.Save(&token.JournalEntry{Job: token.Job{ID: id, State: token.JobQueued}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	journal.Close()
+
+	// the middle line got corrupted, then a crash cut the last one
+	data, _ := ioutil.ReadFile(path)
+	lines := strings.SplitAfter(string(data), "\n")
+	lines[1] = "{broken\n"
+	ioutil.WriteFile(path, []byte(strings.Join(lines, "")+`{"id": "fourth", "sta`), 0600)
+
+	ids := func(journal *token.FileJournal) []string {
+		entries, err := journal.Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := []string{}
+		for _, entry := range entries {
+			ids = append(ids, entry.ID)
+		}
+		return ids
+	}
+
+	journal, err = token.OpenFileJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(journal); !reflect.DeepEqual(got, []string{"first", "third"}) {
+		t.Errorf("expected first and third, got %v", got)
+	}
+	if err := journal.Save(&token.JournalEntry{Job: token.Job{ID: "fifth", State: token.JobQueued}}); err != nil {
+		t.Fatal(err)
+	}
+	journal.Close()
+
+	// nothing after the broken line is compacted away, the broken line is kept for inspection
+	journal, err = token.OpenFileJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer journal.Close()
+	if got := ids(journal); !reflect.DeepEqual(got, []string{"first", "third", "fifth"}) {
+		t.Errorf("expected first, third and fifth, got %v", got)
+	}
+	if data, _ := ioutil.ReadFile(path); !strings.Contains(string(data), "{broken\n") || strings.Contains(string(data), "fourth") {
+		t.Errorf("unexpected file:\n%s", data)
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	env := tokentest.New(t)
+	address := tokentest.NewAddress(t)
+
+	// signed by the previous run, which stopped before broadcasting it
+	nonce, err := env.Chain.PendingNonceAt(context.Background(), *env.Token.CallerAddres)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainID, err := env.Chain.ChainID(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts, err := bind.NewKeyedTransactorWithChainID(env.Chain.Key, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.Nonce, opts.NoSend = new(big.Int).SetUint64(nonce), true
+	tx, err := env.Chain.Token.GrantRole(opts, env.Token.WhitelistedRole, common.HexToAddress(address))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := tx.MarshalBinary()
+
+	journal := token.NewMemoryJournal()
+	history := []token.Transition{{State: token.JobQueued, At: time.Now()}}
+	journal.Save(&token.JournalEntry{Job: token.Job{ID: "queued", Operation: "mint", State: token.JobQueued, History: history}})
+	journal.Save(&token.JournalEntry{Job: token.Job{ID: "signed", Operation: "grantRole", Address: address, State: token.JobSigned, History: history}, RawTx: hexutil.Encode(raw)})
+
+	wlt, err := token.NewWhitelistableTokenWithJournal(env.Chain, env.Chain.Key, env.Chain.ContractAddress, journal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wlt.Close()
+
+	if job, _ := wlt.GetJob("queued"); job.State != token.JobFailed || job.ErrorCode != token.CodeInterrupted {
+		t.Errorf("unexpected job: %+v", job)
+	}
+	if job, _ := wlt.GetJob("signed"); job.State != token.JobBroadcast || job.TransactionHash != tx.Hash().Hex() {
+		t.Errorf("unexpected job: %+v", job)
+	}
+	if !env.HasRole(t, env.Token.WhitelistedRole, address) {
+		t.Errorf("%s is not whitelisted", address)
+	}
+	// the resent nonce is not handed out again
+	if state := wlt.Nonces.State(); state.Next != nonce+1 {
+		t.Errorf("expected next nonce %d, got %+v", nonce+1, state)
+	}
+
+	// known to the node now - watched again without sending it twice
+	again, err := token.NewWhitelistableTokenWithJournal(env.Chain, env.Chain.Key, env.Chain.ContractAddress, journal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer again.Close()
+
+	again.Tracker.Poll()
+	if job, _ := again.GetJob("signed"); job.State != token.JobConfirmed {
+		t.Errorf("unexpected job: %+v", job)
+	}
+	if pending, _ := env.Chain.PendingNonceAt(context.Background(), *env.Token.CallerAddres); pending != nonce+1 {
+		t.Errorf("expected pending nonce %d, got %d", nonce+1, pending)
+	}
+}
+
+func TestBatchJobRestored(t *testing.T) {
+	env := tokentest.New(t)
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+
+	journal, err := token.OpenFileJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wlt, err := token.NewWhitelistableTokenWithJournal(env.Chain, env.Chain.Key, env.Chain.ContractAddress, journal)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := &token.WhitelistMultiInput{Addresses: []token.WhitelistInput{{Address: tokentest.NewAddress(t)}, {Address: ""}}}
+	started, err := wlt.StartWhitelistJob(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := started
+	for deadline := time.Now().Add(10 * time.Second); done.Finished == nil && time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if done, err = wlt.GetBatchJob(started.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wlt.Close()
+
+	// the previous run stopped in the middle of another batch job - one item sent, one not
+	journal, err = token.OpenFileJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	interrupted := &token.BatchJob{ID: "interrupted", Operation: "whitelist", State: token.BatchRunning, Created: time.Now(), TxMultiOutput: token.GetTxMultiOutput(2)}
+	interrupted.Transactions[0].TxOutput = token.TxOutput{Address: input.Addresses[0].Address, JobID: done.Transactions[0].JobID, State: token.ItemWaiting}
+	interrupted.Transactions[1].TxOutput = token.TxOutput{Address: tokentest.NewAddress(t), State: token.ItemWaiting}
+	if err := journal.SaveBatch(interrupted); err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := token.NewWhitelistableTokenWithJournal(env.Chain, env.Chain.Key, env.Chain.ContractAddress, journal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer again.Close()
+
+	if job, err := again.GetBatchJob(started.ID); err != nil || job.State != token.BatchDone || job.Progress.Skipped != 1 || job.Progress.Waiting != 0 {
+		t.Errorf("unexpected batch job: %+v, %v", job, err)
+	}
+	job, err := again.GetBatchJob("interrupted")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.State != token.BatchDone || job.Finished == nil || job.Progress.Failed != 1 || job.Progress.Waiting != 0 {
+		t.Errorf("unexpected batch job: %+v", job)
+	}
+	if item := job.Transactions[1]; item.State != token.JobFailed || item.Error == nil || item.Error.Code != token.CodeInterrupted {
+		t.Errorf("unexpected item: %+v", item)
+	}
+}
@@ -0,0 +1,128 @@
+package token
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+)
+
+// jsonLines file of JSON records, one per line, synced to disk before append returns
+type jsonLines struct {
+	file *os.File
+}
+
+// readJSONLines hands every record of the file at path to replay, a missing file has none.
+// A crash may leave the last line cut - without its newline it was never fully written and is left out,
+// end is where the lines before it end. Any other broken line is skipped and the file isn't intact.
+func readJSONLines(path string, replay func(raw json.RawMessage) error) (intact bool, end int64, err error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return true, 0, nil
+	}
+	if err != nil {
+		return false, 0, err
+	}
+	defer file.Close()
+
+	intact = true
+	reader := bufio.NewReader(file)
+	for number := 1; ; number++ {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			if len(line) > 0 {
+				log.Printf("%s: left out cut last line %d", path, number)
+			}
+			return intact, end, nil
+		}
+		if err != nil {
+			return false, 0, err
+		}
+		end += int64(len(line))
+
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		if err := replay(line); err != nil {
+			log.Printf("%s: skipped broken line %d: %v", path, number, err)
+			intact = false
+		}
+	}
+}
+
+// openJSONLines opens the file at path read up to end for appending. Read intact, it's compacted
+// to records first, otherwise lines which couldn't be read are kept for inspection - only a cut last line goes.
+func openJSONLines(path string, intact bool, end int64, records []interface{}) (*jsonLines, error) {
+	if intact {
+		return writeJSONLines(path, records)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := file.Truncate(end); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &jsonLines{file}, nil
+}
+
+// writeJSONLines replaces the file at path with records and opens it for appending
+func writeJSONLines(path string, records []interface{}) (*jsonLines, error) {
+	tmp, err := os.OpenFile(path+".tmp", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	writer := bufio.NewWriter(tmp)
+	encoder := json.NewEncoder(writer)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			tmp.Close()
+			return nil, err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &jsonLines{file}, nil
+}
+
+// append writes the record as the last line
+func (f *jsonLines) append(record interface{}) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return f.file.Sync()
+}
+
+// Close closes the file
+func (f *jsonLines) Close() error {
+	return f.file.Close()
+}
@@ -0,0 +1,136 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	UnknownRoleError = errors.New("Unknown Role")
+)
+
+// memberBatchSize how many getRoleMember calls go in a single rpc batch
+const memberBatchSize = 200
+
+// RoleByName returns role's id for one of AdminRoleName, MinterRoleName, WhitelistedRoleName
+func (wlt *WhitelistableToken) RoleByName(name string) ([32]byte, error) {
+	role, ok := wlt.Roles()[name]
+	if !ok {
+		return role, UnknownRoleError
+	}
+
+	return role, nil
+}
+
+// GetRoleMembers lists members of the role starting at offset, limit 0 means all remaining members.
+// Everything is read at the same block so pages don't shift while walking the list.
+func (wlt *WhitelistableToken) GetRoleMembers(roleName string, offset, limit uint64) (*RoleMembers, error) {
+	role, err := wlt.RoleByName(roleName)
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := wlt.Backend.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		return nil, err
+	}
+	callOpts := &bind.CallOpts{BlockNumber: header.Number}
+
+	count, err := wlt.Token.GetRoleMemberCount(callOpts, role)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &RoleMembers{
+		Role:    roleName,
+		Total:   count.Uint64(),
+		Offset:  offset,
+		Members: []string{},
+	}
+
+	end := output.Total
+	if limit != 0 && offset+limit < end {
+		end = offset + limit
+	}
+
+	for start := offset; start < end; start += memberBatchSize {
+		stop := start + memberBatchSize
+		if stop > end {
+			stop = end
+		}
+
+		members, err := wlt.getRoleMembersBatch(role, start, stop, header.Number)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range members {
+			output.Members = append(output.Members, m.Hex())
+		}
+	}
+
+	return output, nil
+}
+
+// getRoleMembersBatch reads members [start, stop) with one batched rpc request,
+// falls back to a call per member when the backend has no rpc client
+func (wlt *WhitelistableToken) getRoleMembersBatch(role [32]byte, start, stop uint64, block *big.Int) ([]common.Address, error) {
+	if wlt.RPCClient == nil {
+		members := make([]common.Address, 0, stop-start)
+		for index := start; index < stop; index++ {
+			member, err := wlt.Token.GetRoleMember(&bind.CallOpts{BlockNumber: block}, role, new(big.Int).SetUint64(index))
+			if err != nil {
+				return nil, err
+			}
+			members = append(members, member)
+		}
+		return members, nil
+	}
+
+	parsed, err := tokenABI()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]hexutil.Bytes, stop-start)
+	batch := make([]rpc.BatchElem, stop-start)
+	for i := range batch {
+		data, err := parsed.Pack("getRoleMember", role, new(big.Int).SetUint64(start+uint64(i)))
+		if err != nil {
+			return nil, err
+		}
+
+		batch[i] = rpc.BatchElem{
+			Method: "eth_call",
+			Args: []interface{}{
+				map[string]interface{}{
+					"to":   wlt.ContractAddress,
+					"data": hexutil.Bytes(data),
+				},
+				hexutil.EncodeBig(block),
+			},
+			Result: &results[i],
+		}
+	}
+
+	if err := wlt.RPCClient.BatchCallContext(context.Background(), batch); err != nil {
+		return nil, err
+	}
+
+	members := make([]common.Address, len(batch))
+	for i := range batch {
+		if batch[i].Error != nil {
+			return nil, batch[i].Error
+		}
+		if err := parsed.UnpackIntoInterface(&members[i], "getRoleMember", results[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return members, nil
+}
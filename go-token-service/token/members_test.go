@@ -0,0 +1,61 @@
+package token_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"ERC20Whitelistable/go-token-service/token"
+	"ERC20Whitelistable/go-token-service/tokentest"
+)
+
+// whitelistMembers whitelists n fresh addresses after the deployer, returns them in order
+func whitelistMembers(t *testing.T, env *tokentest.Env, n int) []string {
+	members := []string{}
+	for i := 0; i < n; i++ {
+		address := tokentest.NewAddress(t)
+		env.Whitelist(t, address)
+		members = append(members, address)
+	}
+
+	return members
+}
+
+func TestGetRoleMembers(t *testing.T) {
+	env := tokentest.New(t)
+	members := whitelistMembers(t, env, 3)
+
+	output, err := env.Token.GetRoleMembers(token.WhitelistedRoleName, 2, 0)
+	if err != nil {
+		t.Fatalf("GetRoleMembers failed: %v", err)
+	}
+	if output.Total != 4 || !reflect.DeepEqual(output.Members, members[1:]) {
+		t.Errorf("expected %v of 4, got %+v", members[1:], output)
+	}
+}
+
+func TestGetRoleMembersBatched(t *testing.T) {
+	env := tokentest.New(t)
+	members := whitelistMembers(t, env, 3)
+
+	env.Token.RPCClient = newRPCClient(t, env.Chain, nil)
+	output, err := env.Token.GetRoleMembers(token.WhitelistedRoleName, 1, 2)
+	if err != nil {
+		t.Fatalf("GetRoleMembers failed: %v", err)
+	}
+	if output.Total != 4 || !reflect.DeepEqual(output.Members, members[:2]) {
+		t.Errorf("expected %v of 4, got %+v", members[:2], output)
+	}
+
+	// a single failed call of the batch fails the page
+	failure := errors.New("execution reverted")
+	env.Token.RPCClient = newRPCClient(t, env.Chain, func(method string, n int) error {
+		if n == 1 {
+			return failure
+		}
+		return nil
+	})
+	if _, err := env.Token.GetRoleMembers(token.WhitelistedRoleName, 0, 0); err == nil || err.Error() != failure.Error() {
+		t.Errorf("expected %v, got %v", failure, err)
+	}
+}
@@ -0,0 +1,301 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// node errors which mean our view of the account's nonce is off
+var nonceErrors = []string{
+	"nonce too low",
+	"nonce too high",
+	"invalid transaction nonce",
+	"replacement transaction underpriced",
+	"already known",
+	"known transaction",
+}
+
+// NonceManager hands out nonces of a single account. A nonce is reserved before the send
+// and either committed once the node accepted the transaction or released for reuse,
+// so a failed send never leaves a gap behind. Committed nonces whose transactions got lost
+// are handed out again by Refill.
+type NonceManager struct {
+	backend Backend
+	account common.Address
+
+	next     uint64          // lowest nonce never handed out
+	released []uint64        // handed out but not sent - reused first, ascending
+	reserved map[uint64]bool // handed out and not committed or released yet
+	synced   time.Time       // last resync with the node
+	gapPolls int             // Refills in a row which found a gap
+
+	sync.Mutex
+}
+
+// NonceState snapshot of NonceManager for inspection
+type NonceState struct {
+	Account  string    `json:"account"`
+	Next     uint64    `json:"next"`
+	Released []uint64  `json:"released"`
+	Reserved []uint64  `json:"reserved"`
+	Synced   time.Time `json:"synced"`
+}
+
+// NewNonceManager starts at the account's pending nonce
+func NewNonceManager(backend Backend, account common.Address) (*NonceManager, error) {
+	nm := &NonceManager{
+		backend:  backend,
+		account:  account,
+		released: []uint64{},
+		reserved: map[uint64]bool{},
+	}
+
+	if err := nm.Resync(); err != nil {
+		return nil, err
+	}
+
+	return nm, nil
+}
+
+// Reserve hands out the lowest released nonce or the next one
+func (nm *NonceManager) Reserve() uint64 {
+	nm.Lock()
+	defer nm.Unlock()
+
+	var nonce uint64
+	if len(nm.released) > 0 {
+		nonce = nm.released[0]
+		nm.released = nm.released[1:]
+	} else {
+		nonce = nm.next
+		nm.next++
+	}
+
+	nm.reserved[nonce] = true
+	return nonce
+}
+
+// Commit marks reserved nonce as used by a sent transaction
+func (nm *NonceManager) Commit(nonce uint64) {
+	nm.Lock()
+	defer nm.Unlock()
+
+	delete(nm.reserved, nonce)
+}
+
+// Release gives back reserved nonce whose transaction wasn't sent
+func (nm *NonceManager) Release(nonce uint64) {
+	nm.Lock()
+	defer nm.Unlock()
+
+	if !nm.reserved[nonce] {
+		return
+	}
+	delete(nm.reserved, nonce)
+
+	nm.release(nonce)
+}
+
+// release adds nonces to released, callers hold the lock
+func (nm *NonceManager) release(nonces ...uint64) {
+	nm.released = append(nm.released, nonces...)
+	sort.Slice(nm.released, func(i, j int) bool { return nm.released[i] < nm.released[j] })
+
+	// released nonces at the top are simply not handed out yet
+	for len(nm.released) > 0 && nm.released[len(nm.released)-1] == nm.next-1 {
+		nm.released = nm.released[:len(nm.released)-1]
+		nm.next--
+	}
+}
+
+// Refill hands out again nonces from the node's pending one up to next which nobody holds - not reserved,
+// released or used by a transaction the account still has, as told by used. The node holds back everything
+// above such a gap, it's refilled once it stayed for dropAfterMisses calls or right away when now is set,
+// e.g. a transaction was just dropped. used is called while no nonce can be reserved or committed.
+func (nm *NonceManager) Refill(pending uint64, now bool, used func() map[uint64]bool) {
+	nm.Lock()
+	defer nm.Unlock()
+
+	held := used()
+	for _, nonce := range nm.released {
+		held[nonce] = true
+	}
+
+	missing := []uint64{}
+	for nonce := pending; nonce < nm.next; nonce++ {
+		if !nm.reserved[nonce] && !held[nonce] {
+			missing = append(missing, nonce)
+		}
+	}
+	if len(missing) == 0 {
+		nm.gapPolls = 0
+		return
+	}
+
+	nm.gapPolls++
+	if nm.gapPolls < dropAfterMisses && !now {
+		return
+	}
+	nm.gapPolls = 0
+	nm.release(missing...)
+}
+
+// Resync aligns with the node's pending nonce. Nonces the node already has are dropped,
+// nonces the node never got while nothing is in flight are handed out again.
+func (nm *NonceManager) Resync() error {
+	pending, err := nm.backend.PendingNonceAt(context.Background(), nm.account)
+	if err != nil {
+		return err
+	}
+
+	nm.Lock()
+	defer nm.Unlock()
+
+	released := []uint64{}
+	for _, nonce := range nm.released {
+		if nonce >= pending {
+			released = append(released, nonce)
+		}
+	}
+	nm.released = released
+
+	if pending > nm.next {
+		nm.next = pending
+	} else if pending < nm.next && len(nm.reserved) == 0 {
+		// whatever we sent above pending never made it to the node
+		nm.next = pending
+		nm.released = []uint64{}
+	}
+
+	nm.synced = time.Now()
+	return nil
+}
+
+// State snapshot for inspection
+func (nm *NonceManager) State() *NonceState {
+	nm.Lock()
+	defer nm.Unlock()
+
+	state := &NonceState{
+		Account:  nm.account.Hex(),
+		Next:     nm.next,
+		Released: append([]uint64{}, nm.released...),
+		Reserved: []uint64{},
+		Synced:   nm.synced,
+	}
+	for nonce := range nm.reserved {
+		state.Reserved = append(state.Reserved, nonce)
+	}
+	sort.Slice(state.Reserved, func(i, j int) bool { return state.Reserved[i] < state.Reserved[j] })
+
+	return state
+}
+
+// isNonceError reports node errors caused by a wrong nonce
+func isNonceError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, nonceErr := range nonceErrors {
+		if strings.Contains(msg, nonceErr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isAmbiguous reports send errors which leave open whether the node got the transaction -
+// timeouts and broken connections, unlike answers of the node rejecting it
+func isAmbiguous(err error) bool {
+	var netErr net.Error
+	var httpErr rpc.HTTPError
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled),
+		errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, rpc.ErrClientQuit):
+		return true
+	case errors.As(err, &httpErr):
+		// a proxy may fail after the node got the request
+		return httpErr.StatusCode >= 500
+	}
+
+	return errors.As(err, &netErr)
+}
+
+// send prices, signs and sends job's transaction with its own copy of TransactOpts and a reserved nonce,
+// the job follows along. On nonce errors it resyncs with the node and tries once more.
+// A signed transaction the node may have got keeps its nonce and is watched, see isAmbiguous.
+func (wlt *WhitelistableToken) send(ctx context.Context, job *Job, transact func(*bind.TransactOpts) (*types.Transaction, error)) (*types.Transaction, error) {
+	var tx *types.Transaction
+
+	// priced before a nonce is reserved - a deferred send doesn't hold up the others
+	priced := *wlt.TransactOpts
+	err := wlt.Gas.Price(ctx, &priced)
+	if err != nil {
+		return nil, err
+	}
+
+	// without an estimate the binding estimates on its own
+	if job.EstimatedGas > 0 {
+		priced.GasLimit, err = wlt.Limits.Limit(job.Operation, job.EstimatedGas)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		nonce := wlt.Nonces.Reserve()
+
+		opts := priced
+		opts.Nonce = new(big.Int).SetUint64(nonce)
+		var signed *types.Transaction
+		opts.Signer = func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			tx, err := wlt.TransactOpts.Signer(address, tx)
+			if err != nil {
+				return nil, err
+			}
+
+			// journaled before it's broadcast
+			if err := wlt.Tracker.Signed(job, tx); err != nil {
+				return nil, err
+			}
+			signed = tx
+			return tx, nil
+		}
+
+		tx, err = transact(&opts)
+		if err == nil {
+			wlt.Nonces.Commit(nonce)
+			wlt.Tracker.Broadcast(job)
+			return tx, nil
+		}
+
+		// polling tells whether it got mined, dropped or replaced
+		if signed != nil && isAmbiguous(err) {
+			wlt.Nonces.Commit(nonce)
+			wlt.Tracker.Unanswered(job, err)
+			return signed, nil
+		}
+
+		wlt.Nonces.Release(nonce)
+		if !isNonceError(err) {
+			return nil, err
+		}
+		if syncErr := wlt.Nonces.Resync(); syncErr != nil {
+			return nil, err
+		}
+	}
+
+	return nil, err
+}
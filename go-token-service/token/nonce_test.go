@@ -0,0 +1,149 @@
+package token_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"ERC20Whitelistable/go-token-service/devchain"
+	"ERC20Whitelistable/go-token-service/token"
+	"ERC20Whitelistable/go-token-service/tokentest"
+)
+
+func TestNonceManagerReuse(t *testing.T) {
+	env := tokentest.New(t)
+	nm, err := token.NewNonceManager(env.Chain, *env.Token.CallerAddres)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := nm.State().Next
+
+	first, second, third := nm.Reserve(), nm.Reserve(), nm.Reserve()
+	if first != start || second != start+1 || third != start+2 {
+		t.Fatalf("unexpected nonces %d %d %d from %d", first, second, third, start)
+	}
+
+	// released nonce in the middle is handed out again before new ones
+	nm.Release(second)
+	nm.Commit(first)
+	if reused := nm.Reserve(); reused != second {
+		t.Errorf("expected released nonce %d, got %d", second, reused)
+	}
+
+	// released nonce at the top just moves next back
+	nm.Release(third)
+	state := nm.State()
+	if state.Next != third || len(state.Released) != 0 {
+		t.Errorf("unexpected state: %+v", state)
+	}
+	if !reflect.DeepEqual(state.Reserved, []uint64{second}) {
+		t.Errorf("expected %d reserved, got %v", second, state.Reserved)
+	}
+}
+
+func TestNonceResyncAfterExternalTransaction(t *testing.T) {
+	env := tokentest.New(t)
+
+	// same key sends behind the service's back, its next nonce is now too low
+	opts, err := bind.NewKeyedTransactorWithChainID(env.Chain.Key, env.Chain.Blockchain().Config().ChainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = env.Chain.Token.GrantRole(
+		opts,
+		env.Token.WhitelistedRole,
+		common.HexToAddress(tokentest.NewAddress(t)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	address := tokentest.NewAddress(t)
+	if _, err := env.Token.WhitelistAddress(&token.WhitelistInput{Address: address}); err != nil {
+		t.Fatalf("WhitelistAddress failed: %v", err)
+	}
+	if !env.HasRole(t, env.Token.WhitelistedRole, address) {
+		t.Errorf("%s is not whitelisted", address)
+	}
+
+	pending, _ := env.Chain.PendingNonceAt(context.Background(), *env.Token.CallerAddres)
+	if state := env.Token.Nonces.State(); state.Next != pending {
+		t.Errorf("expected next nonce %d, got %+v", pending, state)
+	}
+}
+
+// unansweredChain times out on every send, deliver decides whether the transaction got through anyway
+type unansweredChain struct {
+	*devchain.Chain
+	deliver bool
+}
+
+func (c *unansweredChain) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if c.deliver {
+		if err := c.Chain.SendTransaction(ctx, tx); err != nil {
+			return err
+		}
+	}
+
+	return context.DeadlineExceeded
+}
+
+func TestSendWithoutAnswer(t *testing.T) {
+	env := tokentest.New(t)
+	backend := &unansweredChain{env.Chain, true}
+	wlt, err := token.NewWhitelistableToken(backend, env.Chain.Key, env.Chain.ContractAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wlt.Close()
+
+	// the node got it - watched until it's mined
+	delivered, err := wlt.WhitelistAddress(&token.WhitelistInput{Address: tokentest.NewAddress(t)})
+	if err != nil || delivered.State != token.JobBroadcast {
+		t.Fatalf("unexpected output %+v, %v", delivered, err)
+	}
+	wlt.Tracker.Poll()
+	if job, _ := wlt.GetJob(delivered.JobID); job.State != token.JobConfirmed {
+		t.Errorf("expected %s, got %+v", token.JobConfirmed, job)
+	}
+
+	// the node never got it - its nonce isn't handed out again, polling drops it
+	backend.deliver = false
+	lost, err := wlt.WhitelistAddress(&token.WhitelistInput{Address: tokentest.NewAddress(t)})
+	if err != nil || lost.State != token.JobBroadcast {
+		t.Fatalf("unexpected output %+v, %v", lost, err)
+	}
+	if state := wlt.Nonces.State(); len(state.Released) != 0 {
+		t.Errorf("expected no released nonces, got %+v", state)
+	}
+	for i := 0; i < 3; i++ {
+		wlt.Tracker.Poll()
+	}
+	dropped, _ := wlt.GetJob(lost.JobID)
+	if dropped.State != token.JobDropped {
+		t.Errorf("expected %s, got %+v", token.JobDropped, dropped)
+	}
+
+	// the dropped nonce is handed out again, the next transaction doesn't wait behind its gap
+	backend.deliver = true
+	address := tokentest.NewAddress(t)
+	next, err := wlt.WhitelistAddress(&token.WhitelistInput{Address: address})
+	if err != nil {
+		t.Fatalf("WhitelistAddress failed: %v", err)
+	}
+	wlt.Tracker.Poll()
+	if job, _ := wlt.GetJob(next.JobID); job.State != token.JobConfirmed || *job.Nonce != *dropped.Nonce {
+		t.Errorf("expected %s with nonce %d, got %+v", token.JobConfirmed, *dropped.Nonce, job)
+	}
+	if !env.HasRole(t, env.Token.WhitelistedRole, address) {
+		t.Errorf("%s is not whitelisted", address)
+	}
+	pending, _ := env.Chain.PendingNonceAt(context.Background(), *wlt.CallerAddres)
+	if state := wlt.Nonces.State(); state.Next != pending {
+		t.Errorf("expected next nonce %d, got %+v", pending, state)
+	}
+}
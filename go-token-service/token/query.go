@@ -0,0 +1,118 @@
+package token
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// role names as declared in the contract
+const (
+	AdminRoleName       = "DEFAULT_ADMIN_ROLE"
+	MinterRoleName      = "MINTER_ROLE"
+	WhitelistedRoleName = "WHITELISTED_ROLE"
+)
+
+// roleNames fixed order keeps outputs stable
+var roleNames = []string{AdminRoleName, MinterRoleName, WhitelistedRoleName}
+
+// Roles maps role names to their on-chain ids
+func (wlt *WhitelistableToken) Roles() map[string][32]byte {
+	return map[string][32]byte{
+		AdminRoleName:       wlt.AdminRole,
+		MinterRoleName:      wlt.MinterRole,
+		WhitelistedRoleName: wlt.WhitelistedRole,
+	}
+}
+
+// GetAddressInfo reads whitelisted flag, balance and role memberships of the address,
+// spender is optional - when given its allowance over address's tokens is added
+func (wlt *WhitelistableToken) GetAddressInfo(address, spender string) (*AddressInfo, error) {
+	// check if address is valid
+	if ok := IsValidAddress(address); !ok {
+		return nil, InvalidAddressError
+	}
+	addr := common.HexToAddress(address)
+
+	balance, err := wlt.Token.BalanceOf(&bind.CallOpts{}, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &AddressInfo{
+		Address: addr.Hex(),
+		Balance: balance.String(),
+		Roles:   []string{},
+	}
+
+	roles := wlt.Roles()
+	for _, name := range roleNames {
+		has, err := wlt.Token.HasRole(&bind.CallOpts{}, roles[name], addr)
+		if err != nil {
+			return nil, err
+		}
+		if has {
+			info.Roles = append(info.Roles, name)
+		}
+		if name == WhitelistedRoleName {
+			info.Whitelisted = has
+		}
+	}
+
+	if spender != "" {
+		allowance, err := wlt.GetAllowance(address, spender)
+		if err != nil {
+			return nil, err
+		}
+		info.Spender = common.HexToAddress(spender).Hex()
+		info.Allowance = allowance.String()
+	}
+
+	return info, nil
+}
+
+// GetAllowance reads how much spender is allowed to transfer on behalf of owner
+func (wlt *WhitelistableToken) GetAllowance(owner, spender string) (*big.Int, error) {
+	// check if addresses are valid
+	if !IsValidAddress(owner) || !IsValidAddress(spender) {
+		return nil, InvalidAddressError
+	}
+
+	return wlt.Token.Allowance(
+		&bind.CallOpts{},
+		common.HexToAddress(owner),
+		common.HexToAddress(spender),
+	)
+}
+
+// GetTokenInfo reads token's metadata and total supply
+func (wlt *WhitelistableToken) GetTokenInfo() (*TokenInfo, error) {
+	name, err := wlt.Token.Name(&bind.CallOpts{})
+	if err != nil {
+		return nil, err
+	}
+
+	symbol, err := wlt.Token.Symbol(&bind.CallOpts{})
+	if err != nil {
+		return nil, err
+	}
+
+	decimals, err := wlt.Token.Decimals(&bind.CallOpts{})
+	if err != nil {
+		return nil, err
+	}
+
+	totalSupply, err := wlt.Token.TotalSupply(&bind.CallOpts{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenInfo{
+		ContractAddress: wlt.ContractAddress.Hex(),
+		Name:            name,
+		Symbol:          symbol,
+		Decimals:        decimals,
+		TotalSupply:     totalSupply.String(),
+	}, nil
+}
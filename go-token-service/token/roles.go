@@ -0,0 +1,149 @@
+package token
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	LastAdminError = errors.New("Refusing to remove the last admin")
+	SelfAdminError = errors.New("Refusing to remove service signer's admin role")
+)
+
+// GrantRole grants any of the contract's roles to the address
+func (wlt *WhitelistableToken) GrantRole(i *RoleInput) (*TxOutput, error) {
+	job := wlt.Tracker.NewJob("grantRole", i.Address)
+
+	role, err := wlt.RoleByName(i.Role)
+	if err != nil {
+		return wlt.Tracker.Fail(job, err)
+	}
+
+	return wlt.roleTx(job, role)
+}
+
+// RevokeRole takes any of the contract's roles away from the address.
+// Refuses to revoke the signer's admin role or the last admin.
+func (wlt *WhitelistableToken) RevokeRole(i *RoleInput) (*TxOutput, error) {
+	job := wlt.Tracker.NewJob("revokeRole", i.Address)
+
+	role, err := wlt.RoleByName(i.Role)
+	if err != nil {
+		return wlt.Tracker.Fail(job, err)
+	}
+
+	if role == wlt.AdminRole {
+		if ok := IsValidAddress(i.Address); !ok {
+			return wlt.Tracker.Fail(job, InvalidAddressError)
+		}
+		if common.HexToAddress(i.Address) == *wlt.CallerAddres {
+			return wlt.Tracker.Fail(job, SelfAdminError)
+		}
+
+		// the next admin revoke checks once this one is sent
+		wlt.AdminRevokes.Lock()
+		defer wlt.AdminRevokes.Unlock()
+
+		if err := wlt.checkNotLastAdmin(common.HexToAddress(i.Address)); err != nil {
+			return wlt.Tracker.Fail(job, err)
+		}
+		defer wlt.AdminRevokes.add(job)
+	}
+
+	return wlt.roleTx(job, role)
+}
+
+// RenounceRole gives up one of the signer's own roles, i.Address is ignored.
+// Renouncing the admin role would lock the service out, so it is refused.
+func (wlt *WhitelistableToken) RenounceRole(i *RoleInput) (*TxOutput, error) {
+	job := wlt.Tracker.NewJob("renounceRole", wlt.CallerAddres.Hex())
+
+	role, err := wlt.RoleByName(i.Role)
+	if err != nil {
+		return wlt.Tracker.Fail(job, err)
+	}
+
+	if role == wlt.AdminRole {
+		return wlt.Tracker.Fail(job, SelfAdminError)
+	}
+
+	return wlt.roleTx(job, role)
+}
+
+// AdminRevokes admin revokes sent but not final yet. Latest state doesn't show them,
+// so without them back-to-back revokes could each pass the last admin check.
+type AdminRevokes struct {
+	jobs map[string]common.Address // revoked admin by job id
+
+	sync.Mutex // held from the last admin check until the revoke is sent
+}
+
+// NewAdminRevokes no revokes in flight
+func NewAdminRevokes() *AdminRevokes {
+	return &AdminRevokes{jobs: map[string]common.Address{}}
+}
+
+// add records the revoke, lock has to be held
+func (r *AdminRevokes) add(job *Job) {
+	r.jobs[job.ID] = common.HexToAddress(job.Address)
+}
+
+// checkNotLastAdmin fails when revoking address leaves no admin, counting admin revokes
+// in flight as done. AdminRevokes lock has to be held.
+func (wlt *WhitelistableToken) checkNotLastAdmin(address common.Address) error {
+	isAdmin, err := wlt.Token.HasRole(&bind.CallOpts{}, wlt.AdminRole, address)
+	if err != nil || !isAdmin {
+		return err
+	}
+
+	count, err := wlt.Token.GetRoleMemberCount(&bind.CallOpts{}, wlt.AdminRole)
+	if err != nil {
+		return err
+	}
+	remaining := count.Int64() - 1
+
+	revoked := map[common.Address]bool{address: true}
+	for id, admin := range wlt.AdminRevokes.jobs {
+		if !wlt.Tracker.inFlight(id) {
+			delete(wlt.AdminRevokes.jobs, id)
+			continue
+		}
+		if revoked[admin] {
+			continue
+		}
+		revoked[admin] = true
+
+		// mined revokes are in latest state already
+		isAdmin, err := wlt.Token.HasRole(&bind.CallOpts{}, wlt.AdminRole, admin)
+		if err != nil {
+			return err
+		}
+		if isAdmin {
+			remaining--
+		}
+	}
+
+	if remaining < 1 {
+		return LastAdminError
+	}
+
+	return nil
+}
+
+// roleTx sends job's (bytes32,address) role method - grantRole, revokeRole or renounceRole - for job's address
+func (wlt *WhitelistableToken) roleTx(job *Job, role [32]byte) (*TxOutput, error) {
+	return wlt.run(wlt.prepareRole(job, role))
+}
+
+// prepareRole checks and estimates job's role method
+func (wlt *WhitelistableToken) prepareRole(job *Job, role [32]byte) (*call, error) {
+	// check if address is valid
+	if ok := IsValidAddress(job.Address); !ok {
+		return wlt.fail(job, InvalidAddressError)
+	}
+
+	return wlt.prepare(job, role, common.HexToAddress(job.Address))
+}
@@ -0,0 +1,130 @@
+package token_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"ERC20Whitelistable/go-token-service/devchain"
+	"ERC20Whitelistable/go-token-service/token"
+	"ERC20Whitelistable/go-token-service/tokentest"
+)
+
+func TestRevokeLastAdminWithRevokeInFlight(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	otherKey, _ := crypto.GenerateKey()
+	other := crypto.PubkeyToAddress(otherKey.PublicKey)
+
+	chain, err := devchain.NewWithKey(key, other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Close()
+	wlt, err := token.NewWhitelistableToken(chain, key, chain.ContractAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wlt.Close()
+
+	third := tokentest.NewAddress(t)
+	for _, address := range []string{other.Hex(), third} {
+		if _, err := wlt.GrantRole(&token.RoleInput{Role: token.AdminRoleName, Address: address}); err != nil {
+			t.Fatalf("can't grant admin to %s: %v", address, err)
+		}
+	}
+
+	// a nonce gap holds the service's next transactions back, third's revoke stays unmined
+	wlt.Nonces.Reserve()
+	if _, err := wlt.RevokeRole(&token.RoleInput{Role: token.AdminRoleName, Address: third}); err != nil {
+		t.Fatalf("RevokeRole failed: %v", err)
+	}
+
+	// the other admin takes the signer's admin role away
+	opts, err := bind.NewKeyedTransactorWithChainID(otherKey, chain.Blockchain().Config().ChainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chain.Token.RevokeRole(opts, wlt.AdminRole, *wlt.CallerAddres); err != nil {
+		t.Fatal(err)
+	}
+	chain.Commit()
+
+	// latest state has two admins left, the revoke in flight takes one of them
+	if _, err := wlt.RevokeRole(&token.RoleInput{Role: token.AdminRoleName, Address: other.Hex()}); err != token.LastAdminError {
+		t.Errorf("expected LastAdminError, got %v", err)
+	}
+}
+
+func TestAutoWhitelistGrantReverted(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	otherKey, _ := crypto.GenerateKey()
+	other := crypto.PubkeyToAddress(otherKey.PublicKey)
+
+	chain, err := devchain.NewWithKey(key, other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Close()
+	wlt, err := token.NewWhitelistableToken(chain, key, chain.ContractAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wlt.Close()
+
+	if _, err := wlt.GrantRole(&token.RoleInput{Role: token.AdminRoleName, Address: other.Hex()}); err != nil {
+		t.Fatal(err)
+	}
+
+	// a nonce gap holds the grant back until the signer isn't admin anymore
+	gap := wlt.Nonces.Reserve()
+	type result struct {
+		output *token.TxOutput
+		err    error
+	}
+	done := make(chan result)
+	go func() {
+		output, err := wlt.Mint(&token.MintInput{Address: tokentest.NewAddress(t), Amount: "10", AutoWhitelist: true})
+		done <- result{output, err}
+	}()
+	for deadline := time.Now().Add(10 * time.Second); wlt.Nonces.State().Next != gap+2; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("grant wasn't sent")
+		}
+	}
+
+	chainID := chain.Blockchain().Config().ChainID
+	opts, err := bind.NewKeyedTransactorWithChainID(otherKey, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chain.Token.RevokeRole(opts, wlt.AdminRole, *wlt.CallerAddres); err != nil {
+		t.Fatal(err)
+	}
+	chain.Commit()
+
+	// filling the gap mines the grant, which reverts now
+	gasPrice, err := chain.SuggestGasPrice(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := types.SignTx(types.NewTransaction(gap, other, big.NewInt(0), 21000, gasPrice, nil), types.LatestSignerForChainID(chainID), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := chain.SendTransaction(context.Background(), tx); err != nil {
+		t.Fatal(err)
+	}
+
+	r := <-done
+	if r.err != token.GrantRevertedError || r.output.Error == nil || r.output.Error.Code != token.CodeNotWhitelisted {
+		t.Errorf("expected GrantRevertedError with code %s, got %+v, %v", token.CodeNotWhitelisted, r.output, r.err)
+	}
+	if job, _ := wlt.GetJob(r.output.JobID); job.State != token.JobFailed || job.ErrorCode != token.CodeNotWhitelisted {
+		t.Errorf("unexpected mint job: %+v", job)
+	}
+}
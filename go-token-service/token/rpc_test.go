@@ -0,0 +1,114 @@
+package token_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"ERC20Whitelistable/go-token-service/devchain"
+)
+
+// ethAPI answers eth_ methods from the simulated chain, so batched rpc paths run in tests
+type ethAPI struct {
+	chain *devchain.Chain
+	fail  func(method string, n int) error // error for the n-th call of method, nil lets it through
+	calls map[string]int
+
+	sync.Mutex
+}
+
+// callArgs transaction object of eth_call
+type callArgs struct {
+	To   common.Address `json:"to"`
+	Data hexutil.Bytes  `json:"data"`
+}
+
+// newRPCClient in-process rpc client on top of the chain, fail may be nil
+func newRPCClient(t *testing.T, chain *devchain.Chain, fail func(method string, n int) error) *rpc.Client {
+	t.Helper()
+
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", &ethAPI{chain: chain, fail: fail, calls: map[string]int{}}); err != nil {
+		t.Fatal(err)
+	}
+	client := rpc.DialInProc(server)
+	t.Cleanup(func() {
+		client.Close()
+		server.Stop()
+	})
+
+	return client
+}
+
+// count counts the call and returns the error it should fail with
+func (api *ethAPI) count(method string) error {
+	api.Lock()
+	defer api.Unlock()
+
+	n := api.calls[method]
+	api.calls[method]++
+	if api.fail == nil {
+		return nil
+	}
+	return api.fail(method, n)
+}
+
+// Call eth_call
+func (api *ethAPI) Call(ctx context.Context, args callArgs, block string) (hexutil.Bytes, error) {
+	if err := api.count("eth_call"); err != nil {
+		return nil, err
+	}
+
+	number, err := hexutil.DecodeBig(block)
+	if err != nil {
+		return nil, err
+	}
+	return api.chain.CallContract(ctx, ethereum.CallMsg{To: &args.To, Data: args.Data}, number)
+}
+
+// GetTransactionReceipt eth_getTransactionReceipt, null until mined
+func (api *ethAPI) GetTransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	if err := api.count("eth_getTransactionReceipt"); err != nil {
+		return nil, err
+	}
+
+	receipt, err := api.chain.TransactionReceipt(ctx, hash)
+	if err == ethereum.NotFound {
+		return nil, nil
+	}
+	if receipt != nil && receipt.Logs == nil {
+		receipt.Logs = []*types.Log{}
+	}
+	return receipt, err
+}
+
+// GetTransactionByHash eth_getTransactionByHash with hash and blockNumber only, null when unknown
+func (api *ethAPI) GetTransactionByHash(ctx context.Context, hash common.Hash) (map[string]interface{}, error) {
+	if err := api.count("eth_getTransactionByHash"); err != nil {
+		return nil, err
+	}
+
+	_, isPending, err := api.chain.TransactionByHash(ctx, hash)
+	if err == ethereum.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]interface{}{"hash": hash, "blockNumber": nil}
+	if !isPending {
+		receipt, err := api.chain.TransactionReceipt(ctx, hash)
+		if err != nil {
+			return nil, err
+		}
+		fields["blockNumber"] = (*hexutil.Big)(receipt.BlockNumber)
+	}
+	return fields, nil
+}
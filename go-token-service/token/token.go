@@ -4,10 +4,8 @@ import (
 	"context"
 	"crypto/ecdsa"
 	"errors"
-	"fmt"
 	"math/big"
-	"sync"
-	"strconv"
+	"time"
 
 	ethereum "github.com/ethereum/go-ethereum"
 
@@ -16,18 +14,22 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
-
-	"golang.org/x/crypto/sha3"
+	"github.com/ethereum/go-ethereum/rpc"
 
 	"ERC20Whitelistable/go-token-service/contracts"
 )
 
 var (
 	InvalidAddressError = errors.New("Invalid Address")
+	GrantRevertedError  = errors.New("Whitelisting Grant Reverted")
 )
 
+// grantTimeout how long an autoWhitelist mint waits for its grant to be mined
+const grantTimeout = 2 * time.Minute
+
 type WhitelistableToken struct {
-	EthClient       *ethclient.Client  // infura client
+	Backend         Backend            // node connection - ethclient or simulated backend
+	RPCClient       *rpc.Client        // raw rpc client behind Backend - used for batched calls, nil if there's none
 	TransactOpts    *bind.TransactOpts // transaction options
 	CallerAddres    *common.Address    // address of the contract's owner
 	ContractAddress *common.Address    // address of the contract's owner
@@ -35,8 +37,18 @@ type WhitelistableToken struct {
 
 	WhitelistedRole [32]byte // simple can do keccak256("WHITELISTED_ROLE")
 	MinterRole      [32]byte // simple can do keccak256("MINTER_ROLE") but taking it from contract is safer
-
-	*sync.Mutex // used to protect TransactOpts.Nonce
+	AdminRole       [32]byte // DEFAULT_ADMIN_ROLE - 0x00
+	Decimals        uint8    // contract's decimals - scales amounts given in tokens
+
+	Nonces  *NonceManager // hands out TransactOpts.Nonce for every transaction
+	Tracker *Tracker      // watches sent transactions until they are confirmed
+	Gas     GasPricer     // sets fees of every transaction right before it's signed
+	Limits  *GasLimits    // gas limit of every transaction from its estimate
+	Batches *BatchLimits  // bounds /multiple batches and batch jobs
+
+	BatchJobs    *BatchJobs    // batches processed in the background
+	AdminRevokes *AdminRevokes // admin revokes sent but not final yet
+	Indexer      *Indexer      // contract's events, nil when disabled
 }
 
 // GetWhitelistableToken generates WhitelistablToken's context needed for contract's method calls
@@ -44,18 +56,80 @@ func GetWhitelistableToken() (*WhitelistableToken, error) {
 	// reading all specific and sensitive data from config file
 	cfg := GetConfig()
 
-	// set up client
-	client, err := ethclient.Dial(fmt.Sprintf("https://%s.infura.io/v3/%s", cfg.Network, cfg.InfuraKey))
+	rpcClient, privateKey, err := dialConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	wlt, err := NewConfiguredToken(
+		ethclient.NewClient(rpcClient),
+		privateKey,
+		common.HexToAddress(cfg.ContractAddress),
+	)
 	if err != nil {
 		return nil, err
 	}
+	wlt.RPCClient = rpcClient
+	wlt.Tracker.UseRPCClient(rpcClient)
+
+	return wlt, nil
+}
+
+// dialConfig connects to the configured node and parses the configured signer's key
+func dialConfig(cfg *appConfig) (*rpc.Client, *ecdsa.PrivateKey, error) {
+	// set up client - http(s), ws(s) or ipc endpoint
+	rpcClient, err := rpc.Dial(cfg.rpcURL())
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// set up keys
 	privateKey, err := crypto.HexToECDSA(cfg.PrivateKey)
+	if err != nil {
+		rpcClient.Close()
+		return nil, nil, err
+	}
+
+	return rpcClient, privateKey, nil
+}
+
+// NewWhitelistableToken generates WhitelistablToken's context on top of any backend,
+// jobs are kept in memory and nothing is indexed
+func NewWhitelistableToken(backend Backend, privateKey *ecdsa.PrivateKey, address common.Address) (*WhitelistableToken, error) {
+	return NewWhitelistableTokenWithJournal(backend, privateKey, address, NewMemoryJournal())
+}
+
+// NewConfiguredToken generates the service's WhitelistablToken's context on top of any backend
+// with the configured journal and indexer
+func NewConfiguredToken(backend Backend, privateKey *ecdsa.PrivateKey, address common.Address) (*WhitelistableToken, error) {
+	cfg := GetConfig()
+
+	journal, err := cfg.Tracker.journal()
 	if err != nil {
 		return nil, err
 	}
 
+	wlt, err := NewWhitelistableTokenWithJournal(backend, privateKey, address, journal)
+	if err != nil {
+		return nil, err
+	}
+
+	indexer, err := cfg.Indexer.indexer(backend, address, wlt.Roles())
+	if err != nil {
+		wlt.Close()
+		return nil, err
+	}
+	wlt.UseIndexer(indexer)
+
+	return wlt, nil
+}
+
+// NewWhitelistableTokenWithJournal generates WhitelistablToken's context on top of any backend with journal,
+// jobs left in it by a previous run are reconciled first
+func NewWhitelistableTokenWithJournal(backend Backend, privateKey *ecdsa.PrivateKey, address common.Address, journal Journal) (obj *WhitelistableToken, err error) {
+	// only non-chain settings are taken from the config here
+	cfg := GetConfig()
+
 	publicKey := privateKey.Public()
 	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
 	if !ok {
@@ -63,27 +137,38 @@ func GetWhitelistableToken() (*WhitelistableToken, error) {
 	}
 	fromAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
 
+	// transactions resent by Reconcile are already counted by the node's pending nonce
+	tracker := NewTracker(backend, fromAddress, cfg.Tracker.confirmations(), cfg.Tracker.pollInterval(), journal)
+	defer func() {
+		if err != nil {
+			tracker.Stop()
+		}
+	}()
+	if err := tracker.Reconcile(); err != nil {
+		return nil, err
+	}
+
 	// set up TransactOpts
-	nonce, err := client.PendingNonceAt(context.Background(), fromAddress)
+	nonces, err := NewNonceManager(backend, fromAddress)
 	if err != nil {
 		return nil, err
 	}
+	tracker.UseNonces(nonces)
 
-	gasPrice, err := client.SuggestGasPrice(context.Background())
+	gas, err := gasPricer(backend, &cfg.Gas)
 	if err != nil {
 		return nil, err
 	}
 
-	trOpts := bind.NewKeyedTransactor(privateKey)
-	// start nonce with one down and increase it just before transaction
-	trOpts.Nonce = big.NewInt(int64(nonce - 1))
-	trOpts.Value = big.NewInt(0)     // in wei
-	trOpts.GasLimit = uint64(300000) // in units
-	trOpts.GasPrice = gasPrice
+	// shared template - every transaction gets its own copy with a reserved nonce and fresh fees
+	trOpts, err := newTransactor(backend, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	trOpts.Value = big.NewInt(0) // in wei
 
 	// contract instance
-	address := common.HexToAddress(cfg.ContractAddress)
-	instance, err := token.NewToken(address, client)
+	instance, err := token.NewToken(address, backend)
 	if err != nil {
 		return nil, err
 	}
@@ -100,166 +185,287 @@ func GetWhitelistableToken() (*WhitelistableToken, error) {
 		return nil, err
 	}
 
-	obj := &WhitelistableToken{
-		client,
+	// AdminRole
+	adminRole, err := instance.DEFAULTADMINROLE(&bind.CallOpts{})
+	if err != nil {
+		return nil, err
+	}
+
+	// Decimals
+	decimals, err := instance.Decimals(&bind.CallOpts{})
+	if err != nil {
+		return nil, err
+	}
+
+	obj = &WhitelistableToken{
+		backend,
+		nil,
 		trOpts,
 		&fromAddress,
 		&address,
 		instance,
 		whitelistedRole,
 		minterRole,
-		&sync.Mutex{},
+		adminRole,
+		decimals,
+		nonces,
+		tracker,
+		gas,
+		cfg.Gas.limits(),
+		cfg.Batch.limits(),
+		NewBatchJobs(journal),
+		NewAdminRevokes(),
+		nil,
+	}
+	if err := obj.BatchJobs.restore(tracker); err != nil {
+		return nil, err
 	}
 
 	return obj, nil
 }
 
-// WhitelistAddress
-func (wlt *WhitelistableToken) WhitelistAddress(i *WhitelistInput) (*TxOutput, error) {
-	txo := &TxOutput{i.Address, "", false}
+// UseIndexer starts indexing in the background with indexer, Close stops it. Nil leaves indexing disabled.
+func (wlt *WhitelistableToken) UseIndexer(indexer *Indexer) {
+	wlt.Indexer = indexer
+	if indexer != nil {
+		indexer.Start()
+	}
+}
 
-	// check if address is valid
-	if ok := IsValidAddress(i.Address); !ok {
-		return txo, InvalidAddressError
+// newTransactor signs for the backend's chain - legacy and dynamic fee transactions alike
+func newTransactor(backend Backend, privateKey *ecdsa.PrivateKey) (*bind.TransactOpts, error) {
+	chainID, err := backend.ChainID(context.Background())
+	if err != nil {
+		return nil, err
 	}
 
-	// check estimateGas
-	if _, err := wlt.egWhitelisting(i.Address); err != nil {
-		return txo, err
+	return bind.NewKeyedTransactorWithChainID(privateKey, chainID)
+}
+
+// Close stops background work
+func (wlt *WhitelistableToken) Close() {
+	wlt.Tracker.Stop()
+	if wlt.Indexer != nil {
+		wlt.Indexer.Stop()
 	}
+}
 
-	// increment nonce at start to prevent "Error: Known Transaction"
-	wlt.incrementNonce()
+// GetJob job by its id or transaction hash, unknown hashes are looked up on chain
+func (wlt *WhitelistableToken) GetJob(idOrHash string) (*Job, error) {
+	if job, ok := wlt.Tracker.Job(idOrHash); ok {
+		return job, nil
+	}
+	if ok := IsValidHash(idOrHash); !ok {
+		return nil, ethereum.NotFound
+	}
 
-	tx, err := wlt.Token.GrantRole(
-		wlt.TransactOpts,
-		wlt.WhitelistedRole,
-		common.HexToAddress(i.Address),
-	)
+	tx, isPending, err := wlt.Backend.TransactionByHash(context.Background(), common.HexToHash(idOrHash))
 	if err != nil {
-		return txo, err
+		return nil, err
 	}
 
-	// can check if transaction is Mined and OK but it will slow down response
-	txo.OK = true // wlt.getStatusOfTX(tx)
-	txo.TransactionHash = tx.Hash().Hex()
-	return txo, nil
+	// sent before the service started - watch it from now on, Poll takes over after this first look
+	external := wlt.Tracker.TrackExternal(tx)
+	wlt.Tracker.check(external, isPending)
+	job, _ := wlt.Tracker.Job(external.ID)
+	return job, nil
 }
 
-// Mint
-func (wlt *WhitelistableToken) Mint(i *MintInput) (*TxOutput, error) {
-	txo := &TxOutput{i.Address, "", false}
+// WhitelistAddress grants WhitelistedRole to the address
+func (wlt *WhitelistableToken) WhitelistAddress(i *WhitelistInput) (*TxOutput, error) {
+	return wlt.run(wlt.prepareWhitelist("grantRole", i))
+}
 
-	// check if address is valid
-	if ok := IsValidAddress(i.Address); !ok {
-		return txo, InvalidAddressError
+// RevokeWhitelist takes WhitelistedRole away from the address
+func (wlt *WhitelistableToken) RevokeWhitelist(i *WhitelistInput) (*TxOutput, error) {
+	return wlt.run(wlt.prepareWhitelist("revokeRole", i))
+}
+
+// prepareWhitelist checks and estimates WhitelistedRole's operation - grantRole or revokeRole
+func (wlt *WhitelistableToken) prepareWhitelist(operation string, i *WhitelistInput) (*call, error) {
+	job, replayed, err := wlt.Tracker.NewKeyedJob(i.ID, operation, i.Address)
+	if err != nil {
+		return wlt.fail(job, err)
+	}
+	if replayed {
+		return &call{job: job, done: true}, nil
 	}
 
-	// check estimateGas
-	if _, err := wlt.egMint(i.Address, i.Amount); err != nil {
-		return txo, err
+	// already whitelisted - no gas for a no-op, unless forced
+	if operation == "grantRole" && !i.Force && IsValidAddress(i.Address) {
+		has, err := wlt.Token.HasRole(&bind.CallOpts{Pending: true}, wlt.WhitelistedRole, common.HexToAddress(i.Address))
+		if err != nil {
+			return wlt.fail(job, err)
+		}
+		if has {
+			wlt.Tracker.Noop(job, ReasonAlreadyWhitelisted)
+			return &call{job: job, done: true}, nil
+		}
 	}
 
-	// increment nonce at start to prevent "Error: Known Transaction"
-	wlt.incrementNonce()
+	return wlt.prepareRole(job, wlt.WhitelistedRole)
+}
 
-	amountInt64, _ := strconv.ParseInt(i.Amount, 10, 64)
-	tx, err := wlt.Token.Mint(
-		wlt.TransactOpts,
-		common.HexToAddress(i.Address),
-		big.NewInt(amountInt64),
-	)
-	if err != nil {
-		return txo, err
+// WhitelistMultiple grants WhitelistedRole to all given addresses, see batch
+func (wlt *WhitelistableToken) WhitelistMultiple(i *WhitelistMultiInput) (*TxMultiOutput, error) {
+	return wlt.batch(wlt.roleItems("grantRole", i))
+}
+
+// RevokeWhitelistMultiple revokes WhitelistedRole for all given addresses, see batch
+func (wlt *WhitelistableToken) RevokeWhitelistMultiple(i *WhitelistMultiInput) (*TxMultiOutput, error) {
+	return wlt.batch(wlt.roleItems("revokeRole", i))
+}
+
+// MintMultiple mints to all given addresses, see batch
+func (wlt *WhitelistableToken) MintMultiple(i *MintMultiInput) (*TxMultiOutput, error) {
+	return wlt.batch(wlt.mintItems(i))
+}
+
+// roleItems batch items of WhitelistedRole's operation - grantRole or revokeRole - for all given addresses
+func (wlt *WhitelistableToken) roleItems(operation string, i *WhitelistMultiInput) ([]string, prepareItem) {
+	addresses := make([]string, len(i.Addresses))
+	for index, addr := range i.Addresses {
+		addresses[index] = addr.Address
 	}
 
-	txo.OK = true
-	txo.TransactionHash = tx.Hash().Hex()
-	return txo, nil
+	return addresses, func(index int) (*call, error) {
+		// skip incorrect inputs
+		if addresses[index] == "" {
+			return nil, EmptyAddressError
+		}
+
+		return wlt.prepareWhitelist(operation, &i.Addresses[index])
+	}
+}
+
+// mintItems batch items minting to all given addresses
+func (wlt *WhitelistableToken) mintItems(i *MintMultiInput) ([]string, prepareItem) {
+	addresses := make([]string, len(i.Mints))
+	for index, mint := range i.Mints {
+		addresses[index] = mint.Address
+	}
+
+	return addresses, func(index int) (*call, error) {
+		// skip incorrect inputs
+		if addresses[index] == "" {
+			return nil, EmptyAddressError
+		}
+
+		return wlt.prepareMint(&i.Mints[index])
+	}
+}
+
+// Mint mints amount of tokens to the address
+func (wlt *WhitelistableToken) Mint(i *MintInput) (*TxOutput, error) {
+	return wlt.run(wlt.prepareMint(i))
 }
 
-// getStatusOfTX checks transaction Status - returns error on Status != 0
-func (wlt *WhitelistableToken) getStatusOfTX(tx *types.Transaction) bool {
-	receipt, err := bind.WaitMined(context.Background(), wlt.EthClient, tx)
+// prepareMint checks and estimates minting
+func (wlt *WhitelistableToken) prepareMint(i *MintInput) (*call, error) {
+	// fingerprinted in base units, so the same amount matches however it's written
+	amount, amountErr := wlt.parseAmount(i.Amount, i.Units)
+	params := []string{i.Amount, i.Units}
+	if amountErr == nil {
+		params = []string{amount.String()}
+	}
+
+	job, replayed, err := wlt.Tracker.NewKeyedJob(i.ID, "mint", i.Address, params...)
 	if err != nil {
-		return false
+		return wlt.fail(job, err)
+	}
+	if replayed {
+		return &call{job: job, done: true}, nil
 	}
 
-	// 0 - on revert or failure and 1 - on success
-	// https://ethereum.stackexchange.com/questions/28889/what-is-the-exact-meaning-of-a-transactions-new-receipt-status-field
-	if receipt.Status != 1 {
-		return false
+	// check if address is valid
+	if ok := IsValidAddress(i.Address); !ok {
+		return wlt.fail(job, InvalidAddressError)
+	}
+
+	if amountErr != nil {
+		return wlt.fail(job, amountErr)
 	}
+	to := common.HexToAddress(i.Address)
 
-	return true
+	// contract reverts in _beforeTokenTransfer otherwise
+	err = wlt.checkWhitelisted(to)
+	if err == NotWhitelistedError && i.AutoWhitelist {
+		return &call{job, []interface{}{to, amount}, false, wlt.autoWhitelist(job, to, amount)}, nil
+	}
+	if err != nil {
+		return wlt.fail(job, err)
+	}
+
+	return wlt.prepare(job, to, amount)
 }
 
-// incrementNonce manages nonce
-func (wlt *WhitelistableToken) incrementNonce() {
-	wlt.Lock()
-	wlt.TransactOpts.Nonce.Add(wlt.TransactOpts.Nonce, big.NewInt(int64(1)))
-	wlt.Unlock()
+// autoWhitelist step of the mint job sending grantRole for its recipient right before the mint,
+// so the grant takes the nonce ahead of it. The mint is estimated once the grant shows in pending
+// state or, with nodes which don't show it there, once it's mined.
+func (wlt *WhitelistableToken) autoWhitelist(job *Job, to common.Address, amount *big.Int) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		// an earlier item of the batch may have granted it already
+		if err := wlt.checkWhitelisted(to); err == NotWhitelistedError {
+			grant := wlt.Tracker.NewJob("grantRole", job.Address)
+			wlt.Tracker.Whitelisting(job, grant)
+
+			c, err := wlt.prepareRole(grant, wlt.WhitelistedRole)
+			output, err := wlt.runContext(ctx, c, err)
+			if err != nil {
+				return err
+			}
+			if err = wlt.checkWhitelisted(to); err == NotWhitelistedError {
+				err = wlt.waitMined(ctx, common.HexToHash(output.TransactionHash))
+			}
+			if err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
+
+		gas, err := wlt.estimate(job.Operation, to, amount)
+		if err != nil {
+			return err
+		}
+		wlt.Tracker.Estimated(job, gas)
+		return nil
+	}
 }
 
-// egGrantRole Estimate Gas for Whitelisting
-func (wlt *WhitelistableToken) egWhitelisting(address string) (uint64, error){
-	// method
-	transferFnSignature := []byte("grantRole(bytes32,address)")
-	hash := sha3.NewLegacyKeccak256()
-	hash.Write(transferFnSignature)
-	methodID := hash.Sum(nil)[:4]
-
-	// address
-	addr := common.HexToAddress(address)
-	paddedAddress := common.LeftPadBytes(addr.Bytes(), 32)
-	// role
-	paddedRole := common.LeftPadBytes(wlt.WhitelistedRole[:], 32)
-
-	var data []byte
-	data = append(data, methodID...)
-	data = append(data, paddedRole...)
-	data = append(data, paddedAddress...)
-
-	gasLimit, err := wlt.EthClient.EstimateGas(context.Background(), ethereum.CallMsg{
-		From: *wlt.CallerAddres,
-		To:   wlt.ContractAddress,
-		Data: data,
-	})
-	if err != nil {
-		return 0, err
+// waitMined waits up to grantTimeout for the transaction's receipt, fails when it reverted
+func (wlt *WhitelistableToken) waitMined(ctx context.Context, hash common.Hash) error {
+	ctx, cancel := context.WithTimeout(ctx, grantTimeout)
+	defer cancel()
+
+	for {
+		receipt, err := wlt.Backend.TransactionReceipt(ctx, hash)
+		if err == nil {
+			if receipt.Status != types.ReceiptStatusSuccessful {
+				return GrantRevertedError
+			}
+			return nil
+		}
+		if err != ethereum.NotFound {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
 	}
-	return gasLimit, nil
 }
 
-// egMint Estimate Gas for minting
-func (wlt *WhitelistableToken) egMint(address, amount string) (uint64, error) {
-	// method
-	transferFnSignature := []byte("mint(address,uint256)")
-	hash := sha3.NewLegacyKeccak256()
-	hash.Write(transferFnSignature)
-	methodID := hash.Sum(nil)[:4]
-
-	// address
-	addr := common.HexToAddress(address)
-	paddedAddress := common.LeftPadBytes(addr.Bytes(), 32)
-
-	// amount
-	amountBN := new(big.Int)
-	amountBN.SetString(amount, 10)
-	paddedAmount := common.LeftPadBytes(amountBN.Bytes(), 32)
-
-	var data []byte
-	data = append(data, methodID...)
-	data = append(data, paddedAddress...)
-	data = append(data, paddedAmount...)
-
-	gasLimit, err := wlt.EthClient.EstimateGas(context.Background(), ethereum.CallMsg{
-		From: *wlt.CallerAddres,
-		To:   wlt.ContractAddress,
-		Data: data,
-	})
-	if err != nil {
-		return 0, err
+// parseAmount amount in base units or, with UnitsTokens, in whole tokens
+func (wlt *WhitelistableToken) parseAmount(amount, units string) (*big.Int, error) {
+	switch units {
+	case "", UnitsBase:
+		return ParseAmount(amount)
+	case UnitsTokens:
+		return ParseDecimalAmount(amount, wlt.Decimals)
 	}
-	return gasLimit, nil
-}
\ No newline at end of file
+
+	return nil, UnknownUnitsError
+}
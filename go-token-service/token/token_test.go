@@ -0,0 +1,703 @@
+package token_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"ERC20Whitelistable/go-token-service/devchain"
+	"ERC20Whitelistable/go-token-service/token"
+	"ERC20Whitelistable/go-token-service/tokentest"
+)
+
+func TestWhitelistAddress(t *testing.T) {
+	env := tokentest.New(t)
+	address := tokentest.NewAddress(t)
+
+	output, err := env.Token.WhitelistAddress(&token.WhitelistInput{Address: address})
+	if err != nil {
+		t.Fatalf("WhitelistAddress failed: %v", err)
+	}
+	if output.State != token.JobBroadcast || output.TransactionHash == "" || output.JobID == "" {
+		t.Errorf("unexpected output: %+v", output)
+	}
+	if !env.HasRole(t, env.Token.WhitelistedRole, address) {
+		t.Errorf("%s is not whitelisted", address)
+	}
+}
+
+func TestWhitelistAddressInvalid(t *testing.T) {
+	env := tokentest.New(t)
+
+	output, err := env.Token.WhitelistAddress(&token.WhitelistInput{Address: "0x1234"})
+	if err != token.InvalidAddressError {
+		t.Errorf("expected InvalidAddressError, got %v", err)
+	}
+	if output.State != token.JobFailed {
+		t.Errorf("unexpected output: %+v", output)
+	}
+}
+
+func TestRevokeWhitelist(t *testing.T) {
+	env := tokentest.New(t)
+	address := tokentest.NewAddress(t)
+	env.Whitelist(t, address)
+
+	if _, err := env.Token.RevokeWhitelist(&token.WhitelistInput{Address: address}); err != nil {
+		t.Fatalf("RevokeWhitelist failed: %v", err)
+	}
+	if env.HasRole(t, env.Token.WhitelistedRole, address) {
+		t.Errorf("%s is still whitelisted", address)
+	}
+}
+
+func TestMint(t *testing.T) {
+	env := tokentest.New(t)
+	address := tokentest.NewAddress(t)
+	env.Whitelist(t, address)
+
+	output, err := env.Token.Mint(&token.MintInput{Address: address, Amount: "1000"})
+	if err != nil {
+		t.Fatalf("Mint failed: %v", err)
+	}
+	if output.State != token.JobBroadcast {
+		t.Errorf("unexpected output: %+v", output)
+	}
+	if balance := env.BalanceOf(t, address); balance.String() != "1000" {
+		t.Errorf("expected balance 1000, got %s", balance)
+	}
+}
+
+func TestMintNotWhitelisted(t *testing.T) {
+	env := tokentest.New(t)
+	address := tokentest.NewAddress(t)
+
+	output, err := env.Token.Mint(&token.MintInput{Address: address, Amount: "1000"})
+	if err == nil {
+		t.Fatal("expected Mint to a non whitelisted address to fail")
+	}
+	if output.State != token.JobFailed || output.TransactionHash != "" {
+		t.Errorf("unexpected output: %+v", output)
+	}
+
+	// failed mint must not burn a nonce - next transaction still goes through
+	env.Whitelist(t, address)
+	if _, err := env.Token.Mint(&token.MintInput{Address: address, Amount: "1"}); err != nil {
+		t.Fatalf("Mint after failure failed: %v", err)
+	}
+	if balance := env.BalanceOf(t, address); balance.String() != "1" {
+		t.Errorf("expected balance 1, got %s", balance)
+	}
+}
+
+func TestConcurrentWhitelisting(t *testing.T) {
+	env := tokentest.New(t)
+
+	addresses := make([]string, 20)
+	for i := range addresses {
+		addresses[i] = tokentest.NewAddress(t)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(addresses))
+	for i, address := range addresses {
+		wg.Add(1)
+		go func(i int, address string) {
+			defer wg.Done()
+			_, errs[i] = env.Token.WhitelistAddress(&token.WhitelistInput{Address: address})
+		}(i, address)
+	}
+	wg.Wait()
+
+	for i, address := range addresses {
+		if errs[i] != nil {
+			t.Errorf("WhitelistAddress(%s) failed: %v", address, errs[i])
+			continue
+		}
+		if !env.HasRole(t, env.Token.WhitelistedRole, address) {
+			t.Errorf("%s is not whitelisted", address)
+		}
+	}
+}
+
+func TestDeploy(t *testing.T) {
+	env := tokentest.New(t)
+	minter, whitelisted := tokentest.NewAddress(t), tokentest.NewAddress(t)
+
+	wlt, output, err := token.Deploy(env.Chain, env.Chain.Key, &token.DeployInput{
+		Minters:     []string{minter},
+		Whitelisted: []string{whitelisted},
+	})
+	if err != nil {
+		t.Fatalf("Deploy failed: %v", err)
+	}
+	if output.ContractAddress == env.Chain.ContractAddress.Hex() || len(output.Grants) != 2 {
+		t.Fatalf("unexpected output: %+v", output)
+	}
+
+	info, err := wlt.GetAddressInfo(minter, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(info.Roles) != 1 || info.Roles[0] != token.MinterRoleName {
+		t.Errorf("expected %s to be minter only, got %v", minter, info.Roles)
+	}
+
+	info, err = wlt.GetAddressInfo(whitelisted, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.Whitelisted {
+		t.Errorf("%s is not whitelisted", whitelisted)
+	}
+}
+
+func TestWriteContractAddress(t *testing.T) {
+	for _, c := range []struct {
+		name, config, expected string
+	}{
+		{
+			"replaced",
+			"{\n    \"rolesAuth\": {\"user\": \"u\"},\n    \"contractAddress\" :  \"old\",\n\t\"privateKey\": \"key\"\n}\n",
+			"{\n    \"rolesAuth\": {\"user\": \"u\"},\n    \"contractAddress\" :  \"0x01\",\n\t\"privateKey\": \"key\"\n}\n",
+		},
+		{
+			"added",
+			"{\n    \"privateKey\": \"key\",\n    \"network\": \"ropsten\"\n}",
+			"{\n    \"contractAddress\": \"0x01\",\n    \"privateKey\": \"key\",\n    \"network\": \"ropsten\"\n}",
+		},
+		{"empty", "{}", `{"contractAddress": "0x01"}`},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			if err := ioutil.WriteFile(path, []byte(c.config), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := token.WriteContractAddress(path, "0x01"); err != nil {
+				t.Fatalf("WriteContractAddress failed: %v", err)
+			}
+
+			// nothing but the address changes
+			byteValue, _ := ioutil.ReadFile(path)
+			if string(byteValue) != c.expected {
+				t.Errorf("expected\n%s\ngot\n%s", c.expected, byteValue)
+			}
+			var fields map[string]interface{}
+			if err := json.Unmarshal(byteValue, &fields); err != nil || fields["contractAddress"] != "0x01" {
+				t.Errorf("unexpected config %s: %v", byteValue, err)
+			}
+		})
+	}
+}
+
+func TestJobLifecycle(t *testing.T) {
+	env := tokentest.New(t)
+	address := tokentest.NewAddress(t)
+
+	output, err := env.Token.WhitelistAddress(&token.WhitelistInput{Address: address})
+	if err != nil {
+		t.Fatalf("WhitelistAddress failed: %v", err)
+	}
+
+	// dev chain mines right away, one block is enough with default confirmations
+	env.Token.Tracker.Poll()
+	job, err := env.Token.GetJob(output.JobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.State != token.JobConfirmed || job.BlockNumber == 0 || job.GasUsed == 0 || job.Nonce == nil {
+		t.Errorf("unexpected job: %+v", job)
+	}
+
+	states := []string{}
+	for _, transition := range job.History {
+		states = append(states, transition.State)
+	}
+	expected := []string{token.JobQueued, token.JobSigned, token.JobBroadcast, token.JobMined, token.JobConfirmed}
+	if strings.Join(states, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected history %v, got %v", expected, states)
+	}
+
+	// same job by its transaction hash
+	if byHash, err := env.Token.GetJob(output.TransactionHash); err != nil || byHash.ID != job.ID {
+		t.Errorf("expected job %s by hash, got %+v, %v", job.ID, byHash, err)
+	}
+}
+
+// lookupsChain records the transactions looked up by hash
+type lookupsChain struct {
+	*devchain.Chain
+	hashes []common.Hash
+
+	sync.Mutex
+}
+
+func (c *lookupsChain) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	c.Lock()
+	c.hashes = append(c.hashes, hash)
+	c.Unlock()
+
+	return c.Chain.TransactionByHash(ctx, hash)
+}
+
+func (c *lookupsChain) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	c.Lock()
+	c.hashes = append(c.hashes, hash)
+	c.Unlock()
+
+	return c.Chain.TransactionReceipt(ctx, hash)
+}
+
+func TestGetJobByUnknownHash(t *testing.T) {
+	env := tokentest.New(t)
+	backend := &lookupsChain{Chain: env.Chain}
+	wlt, err := token.NewWhitelistableToken(backend, env.Chain.Key, env.Chain.ContractAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wlt.Close()
+
+	// watched by wlt, left to its polls
+	if _, err := wlt.WhitelistAddress(&token.WhitelistInput{Address: tokentest.NewAddress(t)}); err != nil {
+		t.Fatal(err)
+	}
+	// sent by someone else
+	external, err := env.Token.WhitelistAddress(&token.WhitelistInput{Address: tokentest.NewAddress(t)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backend.Lock()
+	backend.hashes = nil
+	backend.Unlock()
+
+	job, err := wlt.GetJob(external.TransactionHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.State != token.JobConfirmed || job.TransactionHash != external.TransactionHash || job.GasUsed == 0 {
+		t.Errorf("unexpected job: %+v", job)
+	}
+
+	backend.Lock()
+	defer backend.Unlock()
+	for _, hash := range backend.hashes {
+		if hash.Hex() != external.TransactionHash {
+			t.Errorf("expected lookups of %s only, got %s", external.TransactionHash, hash.Hex())
+		}
+	}
+}
+
+func TestExternalJobOfOtherSender(t *testing.T) {
+	env := tokentest.New(t)
+	env.Whitelist(t, tokentest.NewAddress(t))
+
+	// nonce 0 of another account, the signer's nonce is past it
+	key, _ := crypto.GenerateKey()
+	tx, err := types.SignTx(
+		types.NewTransaction(0, common.HexToAddress(tokentest.NewAddress(t)), big.NewInt(0), 21000, big.NewInt(1), nil),
+		types.LatestSignerForChainID(env.Chain.Blockchain().Config().ChainID),
+		key,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	job := env.Token.Tracker.TrackExternal(tx)
+
+	env.Token.Tracker.Poll()
+	if job, _ := env.Token.GetJob(job.ID); job.State != token.JobBroadcast {
+		t.Errorf("expected %s, got %+v", token.JobBroadcast, job)
+	}
+
+	// unknown to the node - dropped, not replaced
+	env.Token.Tracker.Poll()
+	env.Token.Tracker.Poll()
+	if job, _ := env.Token.GetJob(job.ID); job.State != token.JobDropped {
+		t.Errorf("expected %s, got %+v", token.JobDropped, job)
+	}
+}
+
+func TestPollBatched(t *testing.T) {
+	env := tokentest.New(t)
+	env.Token.Tracker.UseRPCClient(newRPCClient(t, env.Chain, nil))
+
+	output, err := env.Token.WhitelistAddress(&token.WhitelistInput{Address: tokentest.NewAddress(t)})
+	if err != nil {
+		t.Fatalf("WhitelistAddress failed: %v", err)
+	}
+
+	// never sent, the node doesn't know it
+	key, _ := crypto.GenerateKey()
+	tx, err := types.SignTx(
+		types.NewTransaction(0, common.HexToAddress(tokentest.NewAddress(t)), big.NewInt(0), 21000, big.NewInt(1), nil),
+		types.LatestSignerForChainID(env.Chain.Blockchain().Config().ChainID),
+		key,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unknown := env.Token.Tracker.TrackExternal(tx)
+
+	for i := 0; i < 3; i++ {
+		env.Token.Tracker.Poll()
+	}
+	if job, _ := env.Token.GetJob(output.JobID); job.State != token.JobConfirmed || job.GasUsed == 0 || job.EffectiveGasPrice == "" {
+		t.Errorf("unexpected job: %+v", job)
+	}
+	if job, _ := env.Token.GetJob(unknown.ID); job.State != token.JobDropped {
+		t.Errorf("expected %s, got %+v", token.JobDropped, job)
+	}
+}
+
+func TestJobFailedBeforeSending(t *testing.T) {
+	env := tokentest.New(t)
+
+	output, _ := env.Token.WhitelistAddress(&token.WhitelistInput{Address: "0x1234"})
+	job, err := env.Token.GetJob(output.JobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.State != token.JobFailed || job.Error != token.InvalidAddressError.Error() || len(job.History) != 2 {
+		t.Errorf("unexpected job: %+v", job)
+	}
+}
+
+func TestMintAboveInt64(t *testing.T) {
+	env := tokentest.New(t)
+	address := tokentest.NewAddress(t)
+	env.Whitelist(t, address)
+
+	// 10^24 - a million tokens with 18 decimals
+	amount := "1000000000000000000000000"
+	if _, err := env.Token.Mint(&token.MintInput{Address: address, Amount: amount}); err != nil {
+		t.Fatalf("Mint failed: %v", err)
+	}
+	if balance := env.BalanceOf(t, address); balance.String() != amount {
+		t.Errorf("expected balance %s, got %s", amount, balance)
+	}
+
+	if _, err := env.Token.Mint(&token.MintInput{Address: address, Amount: "12abc"}); err != token.InvalidAmountError {
+		t.Errorf("expected InvalidAmountError, got %v", err)
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	env := tokentest.New(t)
+	address := tokentest.NewAddress(t)
+	env.Whitelist(t, address)
+
+	if _, err := env.Token.Simulate("mint", common.HexToAddress(address), big.NewInt(5)); err != nil {
+		t.Fatalf("Simulate failed: %v", err)
+	}
+	// dry-run only - nothing minted
+	if balance := env.BalanceOf(t, address); balance.Sign() != 0 {
+		t.Errorf("expected balance 0, got %s", balance)
+	}
+
+	outputs, err := env.Token.Simulate("hasRole", env.Token.WhitelistedRole, common.HexToAddress(address))
+	if err != nil || len(outputs) != 1 || outputs[0] != true {
+		t.Errorf("unexpected hasRole outputs: %v, %v", outputs, err)
+	}
+
+	if _, err := env.Token.Simulate("mint", common.HexToAddress(tokentest.NewAddress(t)), big.NewInt(5)); err == nil {
+		t.Error("expected mint to a non whitelisted address to revert")
+	}
+}
+
+func TestMintInTokens(t *testing.T) {
+	env := tokentest.New(t)
+	address := tokentest.NewAddress(t)
+	env.Whitelist(t, address)
+
+	if _, err := env.Token.Mint(&token.MintInput{Address: address, Amount: "12.5", Units: token.UnitsTokens}); err != nil {
+		t.Fatalf("Mint failed: %v", err)
+	}
+	expected := new(big.Int).Mul(big.NewInt(125), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(env.Token.Decimals)-1), nil))
+	if balance := env.BalanceOf(t, address); balance.Cmp(expected) != 0 {
+		t.Errorf("expected balance %s, got %s", expected, balance)
+	}
+
+	if _, err := env.Token.Mint(&token.MintInput{Address: address, Amount: "1", Units: "wei"}); err != token.UnknownUnitsError {
+		t.Errorf("expected UnknownUnitsError, got %v", err)
+	}
+}
+
+func TestBatchSendsInInputOrder(t *testing.T) {
+	env := tokentest.New(t)
+	env.Token.Batches = token.NewBatchLimits(4, 100, 100, 1, time.Second)
+
+	input := &token.WhitelistMultiInput{}
+	for i := 0; i < 10; i++ {
+		input.Addresses = append(input.Addresses, token.WhitelistInput{Address: tokentest.NewAddress(t)})
+	}
+
+	output, err := env.Token.WhitelistMultiple(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// estimates run in parallel, nonces still follow the input
+	var previous *uint64
+	for index, tx := range output.Transactions {
+		job, err := env.Token.GetJob(tx.JobID)
+		if err != nil || tx.Index != index || tx.Address != input.Addresses[index].Address || job.Nonce == nil {
+			t.Fatalf("unexpected output %+v, job %+v, %v", tx, job, err)
+		}
+		if previous != nil && *job.Nonce != *previous+1 {
+			t.Errorf("expected nonce %d at %d, got %d", *previous+1, index, *job.Nonce)
+		}
+		previous = job.Nonce
+	}
+}
+
+func TestTransferMultipleBudget(t *testing.T) {
+	env := tokentest.New(t)
+	if _, err := env.Token.Mint(&token.MintInput{Address: env.Token.CallerAddres.Hex(), Amount: "100"}); err != nil {
+		t.Fatal(err)
+	}
+	first, second, third := tokentest.NewAddress(t), tokentest.NewAddress(t), tokentest.NewAddress(t)
+	for _, address := range []string{first, second, third} {
+		env.Whitelist(t, address)
+	}
+
+	// refused items don't take from the balance, later ones still fit
+	output, err := env.Token.TransferMultiple(&token.TransferMultiInput{Transfers: []token.TransferInput{
+		{Address: "0x1234", Amount: "100"},
+		{Address: first, Amount: "60"},
+		{Address: tokentest.NewAddress(t), Amount: "30"},
+		{Address: second, Amount: "50"},
+		{Address: third, Amount: "40"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	codes := []string{token.CodeInvalidAddress, "", token.CodeNotWhitelisted, token.CodeInsufficientBalance, ""}
+	for index, tx := range output.Transactions {
+		code := ""
+		if tx.Error != nil {
+			code = tx.Error.Code
+		}
+		if code != codes[index] {
+			t.Errorf("expected %q at %d, got %+v", codes[index], index, tx)
+		}
+	}
+	if balance := env.BalanceOf(t, third); balance.String() != "40" {
+		t.Errorf("expected balance 40, got %s", balance)
+	}
+}
+
+// waitBatchJob polls the batch job until it's finished
+func waitBatchJob(t *testing.T, env *tokentest.Env, id string) *token.BatchJob {
+	t.Helper()
+
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		job, err := env.Token.GetBatchJob(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Finished != nil {
+			return job
+		}
+	}
+
+	t.Fatalf("batch job %s didn't finish", id)
+	return nil
+}
+
+func TestBatchJob(t *testing.T) {
+	env := tokentest.New(t)
+	first, second := tokentest.NewAddress(t), tokentest.NewAddress(t)
+
+	input := &token.WhitelistMultiInput{Addresses: []token.WhitelistInput{{Address: first}, {Address: ""}, {Address: second}}}
+	started, err := env.Token.StartWhitelistJob(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if started.Progress.Total != 3 || len(started.Transactions) != 3 {
+		t.Fatalf("unexpected batch job: %+v", started)
+	}
+
+	job := waitBatchJob(t, env, started.ID)
+	if job.State != token.BatchDone || job.Progress.Sent != 2 || job.Progress.Skipped != 1 || job.Progress.Waiting != 0 {
+		t.Errorf("unexpected batch job: %+v", job)
+	}
+	for _, address := range []string{first, second} {
+		if !env.HasRole(t, env.Token.WhitelistedRole, address) {
+			t.Errorf("%s is not whitelisted", address)
+		}
+	}
+
+	// items follow their jobs after the batch is done
+	env.Token.Tracker.Poll()
+	if job, _ := env.Token.GetBatchJob(started.ID); job.Transactions[0].State == token.JobBroadcast {
+		t.Errorf("expected item to follow its job, got %+v", job.Transactions[0])
+	}
+
+	if _, err := env.Token.CancelBatchJob(started.ID); err != token.BatchJobFinishedError {
+		t.Errorf("expected BatchJobFinishedError, got %v", err)
+	}
+	if _, err := env.Token.GetBatchJob("unknown"); err != token.UnknownBatchJobError {
+		t.Errorf("expected UnknownBatchJobError, got %v", err)
+	}
+}
+
+func TestCancelBatchJob(t *testing.T) {
+	env := tokentest.New(t)
+	env.Token.Batches = token.NewBatchLimits(1, 100, 100, 1, time.Second)
+
+	input := &token.WhitelistMultiInput{}
+	for i := 0; i < 50; i++ {
+		input.Addresses = append(input.Addresses, token.WhitelistInput{Address: tokentest.NewAddress(t)})
+	}
+
+	started, err := env.Token.StartWhitelistJob(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.Token.CancelBatchJob(started.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// nothing is sent after cancelling
+	job := waitBatchJob(t, env, started.ID)
+	progress := job.Progress
+	if job.State != token.BatchCancelled || progress.Cancelled == 0 || progress.Sent+progress.Cancelled != progress.Total {
+		t.Errorf("unexpected batch job: %+v", job)
+	}
+	for index, tx := range job.Transactions {
+		sent := tx.TransactionHash != ""
+		if sent != env.HasRole(t, env.Token.WhitelistedRole, input.Addresses[index].Address) {
+			t.Errorf("unexpected item %+v", tx)
+		}
+	}
+}
+
+func TestMintIdempotencyKey(t *testing.T) {
+	env := tokentest.New(t)
+	address := tokentest.NewAddress(t)
+
+	// refused before signing - the key is free for a retry
+	input := &token.MintInput{Address: address, Amount: "50", ID: "payment-1"}
+	if output, err := env.Token.Mint(input); err == nil || output.State != token.JobFailed {
+		t.Fatalf("expected mint to non whitelisted address to fail, got %+v, %v", output, err)
+	}
+	env.Whitelist(t, address)
+
+	first, err := env.Token.Mint(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := env.Token.Mint(input)
+	if err != nil || again.JobID != first.JobID || again.TransactionHash != first.TransactionHash {
+		t.Errorf("expected original output %+v, got %+v, %v", first, again, err)
+	}
+	if balance := env.BalanceOf(t, address); balance.String() != "50" {
+		t.Errorf("expected balance 50, got %s", balance)
+	}
+
+	// the same amount written another way is the same request
+	for _, same := range []*token.MintInput{
+		{Address: address, Amount: "050", ID: "payment-1"},
+		{Address: address, Amount: "0.00000000000000005", Units: token.UnitsTokens, ID: "payment-1"},
+	} {
+		if again, err := env.Token.Mint(same); err != nil || again.JobID != first.JobID {
+			t.Errorf("expected original output %+v for %+v, got %+v, %v", first, same, again, err)
+		}
+	}
+
+	output, err := env.Token.Mint(&token.MintInput{Address: address, Amount: "60", ID: "payment-1"})
+	if err != token.IdempotencyKeyReusedError || output.Error.Code != token.CodeIdempotencyKeyReused || output.JobID == first.JobID {
+		t.Errorf("expected IdempotencyKeyReusedError, got %+v, %v", output, err)
+	}
+}
+
+func TestWhitelistSkipsWhitelisted(t *testing.T) {
+	env := tokentest.New(t)
+	address := tokentest.NewAddress(t)
+	env.Whitelist(t, address)
+	next := env.Token.Nonces.State().Next
+
+	output, err := env.Token.WhitelistAddress(&token.WhitelistInput{Address: address})
+	if err != nil || output.State != token.JobNoop || output.Reason != token.ReasonAlreadyWhitelisted || output.TransactionHash != "" {
+		t.Errorf("expected no-op, got %+v, %v", output, err)
+	}
+	if state := env.Token.Nonces.State(); state.Next != next {
+		t.Errorf("expected no nonce used, got %+v", state)
+	}
+
+	output, err = env.Token.WhitelistAddress(&token.WhitelistInput{Address: address, Force: true})
+	if err != nil || output.State != token.JobBroadcast {
+		t.Errorf("expected forced grant to be sent, got %+v, %v", output, err)
+	}
+}
+
+func TestMintAutoWhitelist(t *testing.T) {
+	env := tokentest.New(t)
+	address := tokentest.NewAddress(t)
+
+	if _, err := env.Token.Mint(&token.MintInput{Address: address, Amount: "50"}); err != token.NotWhitelistedError {
+		t.Fatalf("expected NotWhitelistedError, got %v", err)
+	}
+
+	output, err := env.Token.Mint(&token.MintInput{Address: address, Amount: "50", AutoWhitelist: true})
+	if err != nil || output.WhitelistJobID == "" {
+		t.Fatalf("unexpected output: %+v, %v", output, err)
+	}
+
+	// grant goes first by nonce
+	grant, _ := env.Token.GetJob(output.WhitelistJobID)
+	mint, _ := env.Token.GetJob(output.JobID)
+	if grant.Operation != "grantRole" || *grant.Nonce+1 != *mint.Nonce {
+		t.Errorf("expected grant right before mint, got %+v and %+v", grant, mint)
+	}
+	if balance := env.BalanceOf(t, address); balance.String() != "50" {
+		t.Errorf("expected balance 50, got %s", balance)
+	}
+}
+
+func TestMintMultipleAutoWhitelist(t *testing.T) {
+	env := tokentest.New(t)
+	whitelisted, address := tokentest.NewAddress(t), tokentest.NewAddress(t)
+	env.Whitelist(t, whitelisted)
+
+	// grants go out in send order, only the first mint to address needs one
+	output, err := env.Token.MintMultiple(&token.MintMultiInput{Mints: []token.MintInput{
+		{Address: whitelisted, Amount: "10"},
+		{Address: address, Amount: "20", AutoWhitelist: true},
+		{Address: address, Amount: "30", AutoWhitelist: true},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nonces := []uint64{}
+	for _, tx := range output.Transactions {
+		if tx.Error != nil {
+			t.Fatalf("unexpected output: %+v", tx)
+		}
+		if tx.WhitelistJobID != "" {
+			grant, _ := env.Token.GetJob(tx.WhitelistJobID)
+			nonces = append(nonces, *grant.Nonce)
+		}
+		mint, _ := env.Token.GetJob(tx.JobID)
+		nonces = append(nonces, *mint.Nonce)
+	}
+	if len(nonces) != 4 || nonces[1] != nonces[0]+1 || nonces[2] != nonces[1]+1 || nonces[3] != nonces[2]+1 {
+		t.Errorf("expected mint, grant, mint, mint in a row, got nonces %v", nonces)
+	}
+	if balance := env.BalanceOf(t, address); balance.String() != "50" {
+		t.Errorf("expected balance 50, got %s", balance)
+	}
+}
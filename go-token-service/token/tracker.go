@@ -0,0 +1,671 @@
+package token
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// states of a Job
+const (
+	JobQueued    = "queued"    // accepted, nothing signed yet
+	JobSigned    = "signed"    // signed with a reserved nonce
+	JobBroadcast = "broadcast" // accepted by the node
+	JobPending   = "pending"   // seen in the node's pool
+	JobMined     = "mined"     // mined successfully, waiting for confirmations
+	JobConfirmed = "confirmed" // mined successfully with enough confirmations
+	JobFailed    = "failed"    // refused before sending, send failed or mined but reverted
+	JobReplaced  = "replaced"  // its nonce was used by another transaction
+	JobDropped   = "dropped"   // node forgot about it, nonce is still unused
+	JobNoop      = "noop"      // nothing to send, see Job.Reason
+)
+
+// reasons of JobNoop
+const (
+	ReasonAlreadyWhitelisted = "already_whitelisted"
+)
+
+// jobTransitions allowed state changes, mined and failed go back to pending on reorgs
+var jobTransitions = map[string][]string{
+	JobQueued:    {JobSigned, JobFailed, JobNoop},
+	JobSigned:    {JobSigned, JobBroadcast, JobFailed},
+	JobBroadcast: {JobPending, JobMined, JobFailed, JobReplaced, JobDropped},
+	JobPending:   {JobMined, JobFailed, JobReplaced, JobDropped},
+	JobMined:     {JobConfirmed, JobPending},
+	JobFailed:    {JobPending},
+}
+
+const (
+	trackerRetention = 24 * time.Hour // how long finished jobs are kept around
+	dropAfterMisses  = 3              // polls the node doesn't know the transaction before it's dropped
+)
+
+// Transition single state change of a Job
+type Transition struct {
+	State string    `json:"state"`
+	At    time.Time `json:"at"`
+	Note  string    `json:"note,omitempty"`
+}
+
+// Job every operation sent through WhitelistableToken with its lifecycle
+type Job struct {
+	ID                string       `json:"id"`
+	Operation         string       `json:"operation"` // contract method
+	Address           string       `json:"address"`
+	TransactionHash   string       `json:"txHash,omitempty"`
+	Nonce             *uint64      `json:"nonce,omitempty"`
+	EstimatedGas      uint64       `json:"estimatedGas,omitempty"`
+	GasLimit          uint64       `json:"gasLimit,omitempty"`
+	State             string       `json:"state"`
+	Error             string       `json:"error,omitempty"`
+	ErrorCode         string       `json:"errorCode,omitempty"`
+	Reason            string       `json:"reason,omitempty"`         // why a no-op job sent nothing
+	WhitelistJobID    string       `json:"whitelistJobId,omitempty"` // grant the mint waits for, see MintInput.AutoWhitelist
+	BlockNumber       uint64       `json:"blockNumber,omitempty"`
+	Confirmations     uint64       `json:"confirmations,omitempty"`
+	GasUsed           uint64       `json:"gasUsed,omitempty"`
+	EffectiveGasPrice string       `json:"effectiveGasPrice,omitempty"`
+	IdempotencyKey    string       `json:"idempotencyKey,omitempty"`
+	Fingerprint       string       `json:"fingerprint,omitempty"` // of the keyed request's parameters
+	History           []Transition `json:"history"`
+
+	tx     *types.Transaction
+	misses int  // consecutive polls the node didn't know tx
+	final  bool // no more polling
+}
+
+// Tracker keeps jobs and watches their transactions in the background until they have enough confirmations
+type Tracker struct {
+	backend       Backend
+	account       common.Address // signer - Reconcile resends its transactions
+	confirmations uint64
+	interval      time.Duration
+	journal       Journal
+	rpcClient     *rpc.Client   // batches polls when set, see UseRPCClient
+	nonces        *NonceManager // signer's nonces, refilled by polls - see UseNonces
+
+	jobs   map[string]*Job
+	hashes map[common.Hash]*Job
+	keys   map[string]*Job // by idempotency key
+	stop   chan struct{}
+
+	sync.Mutex
+}
+
+// NewTracker starts watching in the background, confirmations counts the mined block itself.
+// Jobs are saved to journal, see Reconcile.
+func NewTracker(backend Backend, account common.Address, confirmations uint64, interval time.Duration, journal Journal) *Tracker {
+	t := &Tracker{
+		backend:       backend,
+		account:       account,
+		confirmations: confirmations,
+		interval:      interval,
+		journal:       journal,
+		jobs:          map[string]*Job{},
+		hashes:        map[common.Hash]*Job{},
+		keys:          map[string]*Job{},
+		stop:          make(chan struct{}),
+	}
+	go t.run()
+
+	return t
+}
+
+// UseRPCClient polls through rpc batches of client, client is the raw connection behind the backend
+func (t *Tracker) UseRPCClient(client *rpc.Client) {
+	t.Lock()
+	defer t.Unlock()
+
+	t.rpcClient = client
+}
+
+// UseNonces refills gaps the signer's lost transactions leave in nonces, see NonceManager.Refill
+func (t *Tracker) UseNonces(nonces *NonceManager) {
+	t.Lock()
+	defer t.Unlock()
+
+	t.nonces = nonces
+}
+
+// Stop ends background watching and closes the journal
+func (t *Tracker) Stop() {
+	close(t.stop)
+	t.journal.Close()
+}
+
+// NewJob queues a job for operation on address
+func (t *Tracker) NewJob(operation, address string) *Job {
+	t.Lock()
+	defer t.Unlock()
+
+	return t.newJob(operation, address)
+}
+
+// newJob queues a job, callers hold the lock
+func (t *Tracker) newJob(operation, address string) *Job {
+	job := &Job{
+		ID:        newID(),
+		Operation: operation,
+		Address:   address,
+		History:   []Transition{},
+	}
+
+	t.jobs[job.ID] = job
+	t.transition(job, JobQueued, "")
+	return job
+}
+
+// TrackExternal watches a transaction the service didn't send itself, e.g. from before a restart
+func (t *Tracker) TrackExternal(tx *types.Transaction) *Job {
+	job := t.NewJob("external", "")
+
+	t.Lock()
+	defer t.Unlock()
+
+	t.setTx(job, tx)
+	t.transition(job, JobSigned, "")
+	t.transition(job, JobBroadcast, "")
+	return job
+}
+
+// Estimated records job's gas estimate, its gas limit is derived from it
+func (t *Tracker) Estimated(job *Job, gas uint64) {
+	t.Lock()
+	defer t.Unlock()
+
+	job.EstimatedGas = gas
+}
+
+// Signed records signed transaction of the job - re-signing on nonce retries replaces it.
+// Fails when the transaction can't be journaled, it must not be broadcast then.
+func (t *Tracker) Signed(job *Job, tx *types.Transaction) error {
+	t.Lock()
+	defer t.Unlock()
+
+	if job.tx != nil {
+		delete(t.hashes, job.tx.Hash())
+	}
+	t.setTx(job, tx)
+	return t.transition(job, JobSigned, "")
+}
+
+// Broadcast records the node accepted job's transaction
+func (t *Tracker) Broadcast(job *Job) {
+	t.Lock()
+	defer t.Unlock()
+
+	t.transition(job, JobBroadcast, "")
+}
+
+// Unanswered records the node didn't answer the send of job's signed transaction,
+// it's watched like a broadcast one
+func (t *Tracker) Unanswered(job *Job, err error) {
+	t.Lock()
+	defer t.Unlock()
+
+	t.transition(job, JobBroadcast, "no answer from the node: "+err.Error())
+}
+
+// Fail records the job failed before or while sending, returns its output with err for convenience
+func (t *Tracker) Fail(job *Job, err error) (*TxOutput, error) {
+	t.Lock()
+	defer t.Unlock()
+
+	job.Error = err.Error()
+	job.ErrorCode = ErrorCode(err)
+	job.final = true
+	t.transition(job, JobFailed, err.Error())
+	return t.output(job), err
+}
+
+// Whitelisting records grant sent ahead of the job for its recipient
+func (t *Tracker) Whitelisting(job, grant *Job) {
+	t.Lock()
+	defer t.Unlock()
+
+	job.WhitelistJobID = grant.ID
+}
+
+// Noop records the job has nothing to send for reason
+func (t *Tracker) Noop(job *Job, reason string) {
+	t.Lock()
+	defer t.Unlock()
+
+	job.Reason = reason
+	job.final = true
+	t.transition(job, JobNoop, reason)
+}
+
+// inFlight reports whether job id was signed and isn't final yet
+func (t *Tracker) inFlight(id string) bool {
+	t.Lock()
+	defer t.Unlock()
+
+	job, ok := t.jobs[id]
+	return ok && job.tx != nil && !job.final
+}
+
+// Output response for the job in its current state
+func (t *Tracker) Output(job *Job) *TxOutput {
+	t.Lock()
+	defer t.Unlock()
+
+	return t.output(job)
+}
+
+// Job snapshot of the job by its id or transaction hash
+func (t *Tracker) Job(idOrHash string) (*Job, bool) {
+	t.Lock()
+	defer t.Unlock()
+
+	job, ok := t.jobs[idOrHash]
+	if !ok && IsValidHash(idOrHash) {
+		job, ok = t.hashes[common.HexToHash(idOrHash)]
+	}
+	if !ok {
+		return nil, false
+	}
+
+	snapshot := *job
+	snapshot.History = append([]Transition{}, job.History...)
+	return &snapshot, true
+}
+
+func (t *Tracker) run() {
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-ticker.C:
+			t.Poll()
+		}
+	}
+}
+
+// Poll checks all unfinished jobs once
+func (t *Tracker) Poll() {
+	ctx := context.Background()
+
+	head, err := t.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return
+	}
+
+	t.Lock()
+	client := t.rpcClient
+	watched := []*Job{}
+	for id, job := range t.jobs {
+		if !job.final && job.tx != nil && job.State != JobSigned {
+			watched = append(watched, job)
+		} else if job.final && time.Since(job.History[len(job.History)-1].At) > trackerRetention {
+			delete(t.jobs, id)
+			if job.tx != nil {
+				delete(t.hashes, job.tx.Hash())
+			}
+			if t.keys[job.IdempotencyKey] == job {
+				delete(t.keys, job.IdempotencyKey)
+			}
+		}
+	}
+	t.Unlock()
+
+	// nonces by sender - external jobs may come from other accounts. Below the latest one they
+	// are used on chain, from the pending one on nothing can be mined yet.
+	accountNonces, pendingNonces := map[common.Address]uint64{}, map[common.Address]uint64{}
+	for _, job := range watched {
+		from, err := sender(job.tx)
+		if err != nil {
+			continue
+		}
+		if _, ok := accountNonces[from]; ok {
+			continue
+		}
+		if accountNonces[from], err = t.backend.NonceAt(ctx, from, nil); err != nil {
+			return
+		}
+		if pendingNonces[from], err = t.backend.PendingNonceAt(ctx, from); err != nil {
+			return
+		}
+	}
+
+	mayBeMined := make([]bool, len(watched))
+	for index, job := range watched {
+		from, _ := sender(job.tx)
+		pending, ok := pendingNonces[from]
+		mayBeMined[index] = !ok || job.tx.Nonce() < pending
+	}
+
+	var sightings []*sighting
+	var errs []error
+	dropped := false
+	if client != nil {
+		sightings, errs = lookupBatch(client, watched, mayBeMined)
+	} else {
+		sightings, errs = make([]*sighting, len(watched)), make([]error, len(watched))
+		for index, job := range watched {
+			sightings[index], errs[index] = t.lookup(job, mayBeMined[index])
+		}
+	}
+
+	// dynamic fee transactions pay base fee of their block plus the tip, every block is fetched once
+	headers := map[uint64]*types.Header{head.Number.Uint64(): head}
+	for index, job := range watched {
+		if errs[index] != nil {
+			continue
+		}
+
+		if receipt := sightings[index].receipt; receipt != nil {
+			block, ok := headers[receipt.BlockNumber.Uint64()]
+			if !ok {
+				block, err = t.backend.HeaderByNumber(ctx, receipt.BlockNumber)
+				if err != nil {
+					continue
+				}
+				headers[receipt.BlockNumber.Uint64()] = block
+			}
+
+			t.Lock()
+			t.mined(job, receipt, block.BaseFee, head.Number.Uint64())
+			t.Unlock()
+			continue
+		}
+
+		from, _ := sender(job.tx)
+		accountNonce, ok := accountNonces[from]
+
+		t.Lock()
+		t.notMined(job, sightings[index].inPool, !sightings[index].known, accountNonce, ok)
+		dropped = dropped || (from == t.account && job.State == JobDropped)
+		t.Unlock()
+	}
+
+	t.refill(pendingNonces, dropped)
+}
+
+// refill lets the signer's nonces fill gaps lost transactions left, right away when one was just dropped
+func (t *Tracker) refill(pendingNonces map[common.Address]uint64, dropped bool) {
+	t.Lock()
+	nonces := t.nonces
+	t.Unlock()
+	if nonces == nil {
+		return
+	}
+
+	pending, ok := pendingNonces[t.account]
+	if !ok {
+		var err error
+		if pending, err = t.backend.PendingNonceAt(context.Background(), t.account); err != nil {
+			return
+		}
+	}
+
+	nonces.Refill(pending, dropped, func() map[uint64]bool {
+		t.Lock()
+		defer t.Unlock()
+
+		used := map[uint64]bool{}
+		for _, job := range t.jobs {
+			if job.tx == nil || job.State == JobDropped || job.tx.Nonce() < pending {
+				continue
+			}
+			if from, err := sender(job.tx); err == nil && from == t.account {
+				used[job.tx.Nonce()] = true
+			}
+		}
+		return used
+	})
+}
+
+// check looks at a single job right away - its receipt and confirmations once mined,
+// failed lookups are left to Poll
+func (t *Tracker) check(job *Job, isPending bool) {
+	if isPending {
+		t.Lock()
+		t.transition(job, JobPending, "")
+		t.Unlock()
+		return
+	}
+
+	ctx := context.Background()
+	receipt, err := t.backend.TransactionReceipt(ctx, job.tx.Hash())
+	if err != nil {
+		return
+	}
+	head, err := t.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return
+	}
+	block, err := t.backend.HeaderByNumber(ctx, receipt.BlockNumber)
+	if err != nil {
+		return
+	}
+
+	t.Lock()
+	t.mined(job, receipt, block.BaseFee, head.Number.Uint64())
+	t.Unlock()
+}
+
+// sighting what the node knows about a watched transaction
+type sighting struct {
+	receipt *types.Receipt // once mined
+	known   bool           // mined or in the node's pool
+	inPool  bool
+}
+
+// lookup asks the node about job's transaction, its receipt only when it may be mined
+func (t *Tracker) lookup(job *Job, mayBeMined bool) (*sighting, error) {
+	ctx := context.Background()
+
+	if mayBeMined {
+		receipt, err := t.backend.TransactionReceipt(ctx, job.tx.Hash())
+		if err != nil && err != ethereum.NotFound {
+			return nil, err
+		}
+		if receipt != nil {
+			return &sighting{receipt, true, false}, nil
+		}
+	}
+
+	_, isPending, err := t.backend.TransactionByHash(ctx, job.tx.Hash())
+	if err != nil && err != ethereum.NotFound {
+		return nil, err
+	}
+
+	return &sighting{nil, err == nil, err == nil && isPending}, nil
+}
+
+// polledTx part of eth_getTransactionByHash's answer lookupBatch looks at
+type polledTx struct {
+	BlockNumber *string `json:"blockNumber"` // nil while in the pool
+}
+
+// lookupBatch is lookup of all jobs in a single rpc batch, errs are by job
+func lookupBatch(client *rpc.Client, jobs []*Job, mayBeMined []bool) ([]*sighting, []error) {
+	receipts := make([]*types.Receipt, len(jobs))
+	txs := make([]*polledTx, len(jobs))
+	errs := make([]error, len(jobs))
+
+	batch, owners := []rpc.BatchElem{}, []int{}
+	for index, job := range jobs {
+		if mayBeMined[index] {
+			batch = append(batch, rpc.BatchElem{
+				Method: "eth_getTransactionReceipt",
+				Args:   []interface{}{job.tx.Hash()},
+				Result: &receipts[index],
+			})
+			owners = append(owners, index)
+		}
+		batch = append(batch, rpc.BatchElem{
+			Method: "eth_getTransactionByHash",
+			Args:   []interface{}{job.tx.Hash()},
+			Result: &txs[index],
+		})
+		owners = append(owners, index)
+	}
+
+	if len(batch) > 0 {
+		if err := client.BatchCallContext(context.Background(), batch); err != nil {
+			for index := range errs {
+				errs[index] = err
+			}
+			return nil, errs
+		}
+	}
+	for i := range batch {
+		if batch[i].Error != nil {
+			errs[owners[i]] = batch[i].Error
+		}
+	}
+
+	sightings := make([]*sighting, len(jobs))
+	for index := range jobs {
+		switch {
+		case receipts[index] != nil:
+			sightings[index] = &sighting{receipts[index], true, false}
+		case txs[index] != nil:
+			sightings[index] = &sighting{nil, true, txs[index].BlockNumber == nil}
+		default:
+			sightings[index] = &sighting{}
+		}
+	}
+
+	return sightings, errs
+}
+
+// mined updates job from its receipt, baseFee is nil before London
+func (t *Tracker) mined(job *Job, receipt *types.Receipt, baseFee *big.Int, head uint64) {
+	job.misses = 0
+	job.BlockNumber = receipt.BlockNumber.Uint64()
+	job.GasUsed = receipt.GasUsed
+	job.EffectiveGasPrice = job.tx.GasPrice().String()
+	if baseFee != nil {
+		job.EffectiveGasPrice = new(big.Int).Add(baseFee, job.tx.EffectiveGasTipValue(baseFee)).String()
+	}
+	if head >= job.BlockNumber {
+		job.Confirmations = head - job.BlockNumber + 1
+	}
+
+	// 0 - on revert or failure and 1 - on success
+	// https://ethereum.stackexchange.com/questions/28889/what-is-the-exact-meaning-of-a-transactions-new-receipt-status-field
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		job.Error, job.ErrorCode = "transaction reverted", CodeReverted
+		t.transition(job, JobFailed, job.Error)
+	} else {
+		t.transition(job, JobMined, "")
+		if job.Confirmations >= t.confirmations {
+			t.transition(job, JobConfirmed, "")
+		}
+	}
+
+	job.final = job.Confirmations >= t.confirmations
+}
+
+// notMined updates job which has no receipt, accountNonce of tx's sender is known when checked
+func (t *Tracker) notMined(job *Job, inPool, unknown bool, accountNonce uint64, checked bool) {
+	// mined before - dropped out of the chain by a reorg
+	if job.State == JobMined || job.State == JobFailed {
+		job.BlockNumber, job.Confirmations, job.GasUsed, job.EffectiveGasPrice, job.Error, job.ErrorCode = 0, 0, 0, "", "", ""
+		t.transition(job, JobPending, "reorg")
+	}
+
+	switch {
+	case checked && job.tx.Nonce() < accountNonce:
+		job.final = true
+		t.transition(job, JobReplaced, "nonce used by another transaction")
+	case unknown:
+		job.misses++
+		if job.misses >= dropAfterMisses {
+			job.final = true
+			t.transition(job, JobDropped, "unknown to the node")
+		}
+	case inPool:
+		job.misses = 0
+		t.transition(job, JobPending, "")
+	}
+}
+
+// sender recovers the account which signed tx
+func sender(tx *types.Transaction) (common.Address, error) {
+	var signer types.Signer = types.HomesteadSigner{}
+	if tx.Protected() {
+		signer = types.LatestSignerForChainID(tx.ChainId())
+	}
+
+	return types.Sender(signer, tx)
+}
+
+// setTx links transaction to the job
+func (t *Tracker) setTx(job *Job, tx *types.Transaction) {
+	nonce := tx.Nonce()
+	job.tx = tx
+	job.Nonce = &nonce
+	job.GasLimit = tx.Gas()
+	job.TransactionHash = tx.Hash().Hex()
+	t.hashes[tx.Hash()] = job
+}
+
+// transition moves the job to state if allowed and journals it, staying in the same state is a no-op
+func (t *Tracker) transition(job *Job, state, note string) error {
+	if job.State == state && state != JobSigned {
+		return nil
+	}
+
+	if job.State != "" {
+		allowed := false
+		for _, next := range jobTransitions[job.State] {
+			allowed = allowed || next == state
+		}
+		if !allowed {
+			log.Printf("Job %s: transition %s -> %s not allowed", job.ID, job.State, state)
+			return nil
+		}
+	}
+
+	job.State = state
+	job.History = append(job.History, Transition{state, time.Now(), note})
+
+	err := t.record(job)
+	if err != nil {
+		log.Printf("Job %s: can't journal %s: %v", job.ID, state, err)
+	}
+	return err
+}
+
+// newID random hex id of jobs and batch jobs
+func newID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
+
+// output response for the job
+func (t *Tracker) output(job *Job) *TxOutput {
+	output := &TxOutput{
+		Address:         job.Address,
+		TransactionHash: job.TransactionHash,
+		JobID:           job.ID,
+		State:           job.State,
+		EstimatedGas:    job.EstimatedGas,
+		GasUsed:         job.GasUsed,
+		Reason:          job.Reason,
+		WhitelistJobID:  job.WhitelistJobID,
+	}
+	if job.Error != "" {
+		output.Error = &TxError{job.ErrorCode, job.Error}
+	}
+
+	return output
+}
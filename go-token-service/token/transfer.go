@@ -0,0 +1,155 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	NotWhitelistedError        = errors.New("Recipient Not Whitelisted")
+	InsufficientBalanceError   = errors.New("Insufficient Balance")
+	InsufficientAllowanceError = errors.New("Insufficient Allowance")
+)
+
+// Transfer sends tokens from the signer's balance to a whitelisted recipient
+func (wlt *WhitelistableToken) Transfer(i *TransferInput) (*TxOutput, error) {
+	job := wlt.Tracker.NewJob("transfer", i.Address)
+
+	amount, err := ParseAmount(i.Amount)
+	if err != nil {
+		return wlt.Tracker.Fail(job, err)
+	}
+
+	// pending state counts transfers which are sent but not mined yet
+	balance, err := wlt.Token.BalanceOf(&bind.CallOpts{Pending: true}, *wlt.CallerAddres)
+	if err != nil {
+		return wlt.Tracker.Fail(job, err)
+	}
+	if balance.Cmp(amount) < 0 {
+		return wlt.Tracker.Fail(job, InsufficientBalanceError)
+	}
+
+	return wlt.run(wlt.prepareTransfer(job, amount))
+}
+
+// TransferMultiple sends tokens to all recipients, see batch. Transfers which passed their checks
+// take their amount from the signer's balance in input order, ones which don't fit are refused.
+func (wlt *WhitelistableToken) TransferMultiple(i *TransferMultiInput) (*TxMultiOutput, error) {
+	balance, balanceErr := wlt.Token.BalanceOf(&bind.CallOpts{Pending: true}, *wlt.CallerAddres)
+	available := new(big.Int)
+	if balanceErr == nil {
+		available.Set(balance)
+	}
+
+	addresses := make([]string, len(i.Transfers))
+	for index, t := range i.Transfers {
+		addresses[index] = t.Address
+	}
+
+	return wlt.batch(addresses, func(index int) (*call, error) {
+		// skip incorrect inputs
+		if addresses[index] == "" {
+			return nil, EmptyAddressError
+		}
+
+		job := wlt.Tracker.NewJob("transfer", addresses[index])
+		if balanceErr != nil {
+			return wlt.fail(job, balanceErr)
+		}
+		amount, err := ParseAmount(i.Transfers[index].Amount)
+		if err != nil {
+			return wlt.fail(job, err)
+		}
+		if balance.Cmp(amount) < 0 {
+			return wlt.fail(job, InsufficientBalanceError)
+		}
+
+		c, err := wlt.prepareTransfer(job, amount)
+		if err != nil {
+			return c, err
+		}
+
+		// sends run in input order, so the budget is taken in input order as well
+		c.before = func(ctx context.Context) error {
+			if available.Cmp(amount) < 0 {
+				return InsufficientBalanceError
+			}
+			available.Sub(available, amount)
+			return nil
+		}
+		return c, nil
+	})
+}
+
+// TransferFrom moves tokens of an owner who approved the signer to a whitelisted recipient
+func (wlt *WhitelistableToken) TransferFrom(i *TransferFromInput) (*TxOutput, error) {
+	job := wlt.Tracker.NewJob("transferFrom", i.Address)
+
+	// check if addresses are valid
+	if !IsValidAddress(i.From) || !IsValidAddress(i.Address) {
+		return wlt.Tracker.Fail(job, InvalidAddressError)
+	}
+	from := common.HexToAddress(i.From)
+	to := common.HexToAddress(i.Address)
+
+	amount, err := ParseAmount(i.Amount)
+	if err != nil {
+		return wlt.Tracker.Fail(job, err)
+	}
+
+	if err := wlt.checkWhitelisted(to); err != nil {
+		return wlt.Tracker.Fail(job, err)
+	}
+
+	allowance, err := wlt.Token.Allowance(&bind.CallOpts{Pending: true}, from, *wlt.CallerAddres)
+	if err != nil {
+		return wlt.Tracker.Fail(job, err)
+	}
+	if allowance.Cmp(amount) < 0 {
+		return wlt.Tracker.Fail(job, InsufficientAllowanceError)
+	}
+
+	balance, err := wlt.Token.BalanceOf(&bind.CallOpts{Pending: true}, from)
+	if err != nil {
+		return wlt.Tracker.Fail(job, err)
+	}
+	if balance.Cmp(amount) < 0 {
+		return wlt.Tracker.Fail(job, InsufficientBalanceError)
+	}
+
+	return wlt.transact(job, from, to, amount)
+}
+
+// prepareTransfer checks job's recipient and estimates sending amount from the signer's balance
+func (wlt *WhitelistableToken) prepareTransfer(job *Job, amount *big.Int) (*call, error) {
+	// check if address is valid
+	if ok := IsValidAddress(job.Address); !ok {
+		return wlt.fail(job, InvalidAddressError)
+	}
+	to := common.HexToAddress(job.Address)
+
+	// contract reverts in _beforeTokenTransfer otherwise
+	if err := wlt.checkWhitelisted(to); err != nil {
+		return wlt.fail(job, err)
+	}
+
+	return wlt.prepare(job, to, amount)
+}
+
+// checkWhitelisted fails with NotWhitelistedError when address can't receive tokens
+func (wlt *WhitelistableToken) checkWhitelisted(address common.Address) error {
+	// pending - grants just sent count, estimates run on top of them as well
+	whitelisted, err := wlt.Token.HasRole(&bind.CallOpts{Pending: true}, wlt.WhitelistedRole, address)
+	if err != nil {
+		return err
+	}
+	if !whitelisted {
+		return NotWhitelistedError
+	}
+
+	return nil
+}
@@ -7,43 +7,165 @@ import (
 // WhitelistInput simple wrapper for WhitelistAddress() inputs
 type WhitelistInput struct {
 	Address string `json:"address"`
+	ID      string `json:"id,omitempty"`    // idempotency key - a repeated request returns the original output
+	Force   bool   `json:"force,omitempty"` // grant even when the address is already whitelisted
 }
 
 type WhitelistMultiInput struct {
 	Addresses []WhitelistInput `json:"addresses"`
 }
 
+// RoleInput simple wrapper for GrantRole(), RevokeRole() and RenounceRole() inputs
+type RoleInput struct {
+	Role    string `json:"role"`    // DEFAULT_ADMIN_ROLE, MINTER_ROLE or WHITELISTED_ROLE
+	Address string `json:"address"` // ignored by RenounceRole() - only the signer can renounce its own roles
+}
+
 // WhitelistInput simple wrapper for Mint() inputs
 type MintInput struct {
 	Address string `json:"address"`
 	Amount  string `json:"amount"`
+	Units   string `json:"units,omitempty"` // base (default) or tokens - "12.5" tokens is scaled by contract's decimals
+	ID      string `json:"id,omitempty"`    // idempotency key - a repeated request returns the original output
+
+	AutoWhitelist bool `json:"autoWhitelist,omitempty"` // grant WhitelistedRole first when the recipient lacks it
 }
 
 type MintMultiInput struct {
 	Mints []MintInput `json:"mints"`
 }
 
-// TxOutput simple wrapper for WhitelistAddress() and Mint() outputs
+// TransferInput simple wrapper for Transfer() inputs
+type TransferInput struct {
+	Address string `json:"address"` // recipient
+	Amount  string `json:"amount"`
+}
+
+type TransferMultiInput struct {
+	Transfers []TransferInput `json:"transfers"`
+}
+
+// TransferFromInput simple wrapper for TransferFrom() inputs
+type TransferFromInput struct {
+	From    string `json:"from"`    // owner who approved the signer
+	Address string `json:"address"` // recipient
+	Amount  string `json:"amount"`
+}
+
+// AllowanceInput simple wrapper for Approve(), IncreaseAllowance() and DecreaseAllowance() inputs
+type AllowanceInput struct {
+	Spender string `json:"spender"`
+	Amount  string `json:"amount"`
+}
+
+// AllowanceOutput simple wrapper for GetAllowance() output
+type AllowanceOutput struct {
+	Owner     string `json:"owner"`
+	Spender   string `json:"spender"`
+	Allowance string `json:"allowance"`
+}
+
+// EventsOutput simple wrapper for indexed events, Cursor is the last indexed block
+type EventsOutput struct {
+	Cursor *BlockRef `json:"cursor"`
+	Events []Event   `json:"events"`
+	Next   string    `json:"next,omitempty"` // position the next page goes on after, set when this one is full
+}
+
+// DeployInput simple wrapper for Deploy() inputs - roles granted right after deployment
+type DeployInput struct {
+	Minters     []string `json:"minters"`
+	Whitelisted []string `json:"whitelisted"`
+}
+
+// DeployOutput simple wrapper for Deploy() output
+type DeployOutput struct {
+	ContractAddress string     `json:"contractAddress"`
+	TransactionHash string     `json:"txHash"`
+	BlockNumber     uint64     `json:"blockNumber"`
+	Grants          []TxOutput `json:"grants"`
+}
+
+// TxOutput simple wrapper for outputs of all transactions sent by WhitelistableToken
 type TxOutput struct {
-	Address         string `json:"address"`
-	TransactionHash string `json:"txHash"`
-	OK              bool   `json:"ok"`
+	Address         string   `json:"address"`
+	TransactionHash string   `json:"txHash"`
+	JobID           string   `json:"jobId"` // GET /tx/{jobId} follows the job further
+	State           string   `json:"state"` // job's state when the response was made
+	EstimatedGas    uint64   `json:"estimatedGas,omitempty"`
+	GasUsed         uint64   `json:"gasUsed,omitempty"`        // once mined
+	Reason          string   `json:"reason,omitempty"`         // why a noop sent nothing, e.g. already_whitelisted
+	WhitelistJobID  string   `json:"whitelistJobId,omitempty"` // grant sent first by autoWhitelist
+	Error           *TxError `json:"error,omitempty"`
+}
+
+// ItemSkipped state of batch items which never became a job
+const ItemSkipped = "skipped"
+
+// TxItemOutput single item of a batch, Index is its position in the input
+type TxItemOutput struct {
+	Index int `json:"index"`
+	TxOutput
+}
+
+// AddressInfo simple wrapper for GetAddressInfo() output
+type AddressInfo struct {
+	Address     string   `json:"address"`
+	Whitelisted bool     `json:"whitelisted"`
+	Balance     string   `json:"balance"`
+	Roles       []string `json:"roles"`
+	Spender     string   `json:"spender,omitempty"`
+	Allowance   string   `json:"allowance,omitempty"`
 }
 
+// TokenInfo simple wrapper for GetTokenInfo() output
+type TokenInfo struct {
+	ContractAddress string `json:"contractAddress"`
+	Name            string `json:"name"`
+	Symbol          string `json:"symbol"`
+	Decimals        uint8  `json:"decimals"`
+	TotalSupply     string `json:"totalSupply"`
+}
+
+// RoleMembers simple wrapper for GetRoleMembers() output
+type RoleMembers struct {
+	Role    string   `json:"role"`
+	Total   uint64   `json:"total"`
+	Offset  uint64   `json:"offset"`
+	Members []string `json:"members"`
+}
+
+// TxMultiOutput batch output, Transactions are in input order
 type TxMultiOutput struct {
 	*sync.Mutex
-	Transactions []TxOutput `json:"txsHash"`
+	Transactions []TxItemOutput `json:"txsHash"`
 }
 
-func GetTxMultiOutput() *TxMultiOutput {
-	return &TxMultiOutput{
+// GetTxMultiOutput output for a batch of size items
+func GetTxMultiOutput(size int) *TxMultiOutput {
+	txm := &TxMultiOutput{
 		&sync.Mutex{},
-		[]TxOutput{},
+		make([]TxItemOutput, size),
 	}
+	for i := range txm.Transactions {
+		txm.Transactions[i].Index = i
+	}
+
+	return txm
 }
 
-func (txm *TxMultiOutput) Add(tx *TxOutput) {
+// Set output of the item at index
+func (txm *TxMultiOutput) Set(index int, tx *TxOutput) {
 	txm.Lock()
-	txm.Transactions = append(txm.Transactions, *tx)
+	txm.Transactions[index].TxOutput = *tx
 	txm.Unlock()
 }
+
+// Skip marks the item at index as never sent because of err
+func (txm *TxMultiOutput) Skip(index int, address string, err error) {
+	txm.Set(index, &TxOutput{
+		Address: address,
+		State:   ItemSkipped,
+		Error:   &TxError{ErrorCode(err), err.Error()},
+	})
+}
@@ -1,11 +1,65 @@
 package token
 
 import (
+	"errors"
+	"math/big"
 	"regexp"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
 )
 
+var (
+	InvalidAmountError   = errors.New("Invalid Amount")
+	NegativeAmountError  = errors.New("Negative Amount")
+	TooManyDecimalsError = errors.New("Amount Has More Decimals Than The Token")
+	UnknownUnitsError    = errors.New("Unknown Units")
+)
+
+// units of an amount
+const (
+	UnitsBase   = "base"   // token's smallest unit, the default
+	UnitsTokens = "tokens" // whole tokens, scaled by the contract's decimals
+)
+
+var amountRe = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+// ParseAmount parses non-negative base 10 integer amount in token's base units
+func ParseAmount(amount string) (*big.Int, error) {
+	return ParseDecimalAmount(amount, 0)
+}
+
+// ParseDecimalAmount parses non-negative base 10 amount with up to decimals fractional digits
+// and scales it by 10^decimals - "12.5" with 18 decimals is 12500000000000000000.
+// Trailing zeros past decimals are fine, any other digit there is TooManyDecimalsError.
+func ParseDecimalAmount(amount string, decimals uint8) (*big.Int, error) {
+	if ok := amountRe.MatchString(amount); !ok {
+		return nil, InvalidAmountError
+	}
+	if strings.HasPrefix(amount, "-") {
+		return nil, NegativeAmountError
+	}
+
+	whole, fraction := amount, ""
+	if dot := strings.Index(amount, "."); dot >= 0 {
+		whole, fraction = amount[:dot], amount[dot+1:]
+	}
+
+	fraction = strings.TrimRight(fraction, "0")
+	if len(fraction) > int(decimals) {
+		return nil, TooManyDecimalsError
+	}
+	fraction += strings.Repeat("0", int(decimals)-len(fraction))
+
+	amountBN, _ := new(big.Int).SetString(whole+fraction, 10)
+	// uint256 on chain
+	if amountBN.BitLen() > 256 {
+		return nil, InvalidAmountError
+	}
+
+	return amountBN, nil
+}
+
 // IsValidAddress validate hex address
 func IsValidAddress(iaddress interface{}) bool {
 	re := regexp.MustCompile("^0x[0-9a-fA-F]{40}$")
@@ -18,3 +72,9 @@ func IsValidAddress(iaddress interface{}) bool {
 		return false
 	}
 }
+
+// IsValidHash validate hex transaction hash
+func IsValidHash(hash string) bool {
+	re := regexp.MustCompile("^0x[0-9a-fA-F]{64}$")
+	return re.MatchString(hash)
+}
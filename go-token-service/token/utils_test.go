@@ -0,0 +1,45 @@
+package token_test
+
+import (
+	"testing"
+
+	"ERC20Whitelistable/go-token-service/token"
+)
+
+func TestParseDecimalAmount(t *testing.T) {
+	tests := []struct {
+		amount   string
+		decimals uint8
+		expected string
+		err      error
+	}{
+		{"12.5", 18, "12500000000000000000", nil},
+		{"12", 18, "12000000000000000000", nil},
+		{"0.000000000000000001", 18, "1", nil},
+		{"1.50", 1, "15", nil}, // trailing zeros past decimals are fine
+		{"100000000000000000000", 0, "100000000000000000000", nil},
+		{"0.0000000000000000001", 18, "", token.TooManyDecimalsError},
+		{"1.5", 0, "", token.TooManyDecimalsError},
+		{"-1", 18, "", token.NegativeAmountError},
+		{"", 18, "", token.InvalidAmountError},
+		{"1e18", 18, "", token.InvalidAmountError},
+		{".5", 18, "", token.InvalidAmountError},
+		{"1.", 18, "", token.InvalidAmountError},
+		{"+1", 18, "", token.InvalidAmountError},
+		{" 1", 18, "", token.InvalidAmountError},
+		{"1,5", 18, "", token.InvalidAmountError},
+		// 2^256 doesn't fit uint256
+		{"115792089237316195423570985008687907853269984665640564039457584007913129639936", 0, "", token.InvalidAmountError},
+	}
+
+	for _, test := range tests {
+		amount, err := token.ParseDecimalAmount(test.amount, test.decimals)
+		if err != test.err {
+			t.Errorf("%q with %d decimals: expected error %v, got %v", test.amount, test.decimals, test.err, err)
+			continue
+		}
+		if err == nil && amount.String() != test.expected {
+			t.Errorf("%q with %d decimals: expected %s, got %s", test.amount, test.decimals, test.expected, amount)
+		}
+	}
+}
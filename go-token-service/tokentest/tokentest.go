@@ -0,0 +1,85 @@
+// Package tokentest provides a simulated chain with ERC20Whitelistable deployed
+// and a WhitelistableToken on top of it, for tests of packages building on token.
+package tokentest
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"ERC20Whitelistable/go-token-service/devchain"
+	"ERC20Whitelistable/go-token-service/token"
+)
+
+// Env test environment - simulated chain and token context signed by the deployer
+type Env struct {
+	Chain *devchain.Chain
+	Token *token.WhitelistableToken
+}
+
+// New deploys the contract on a fresh simulated chain, the chain is closed with the test
+func New(t testing.TB) *Env {
+	t.Helper()
+
+	chain, err := devchain.New()
+	if err != nil {
+		t.Fatalf("can't start simulated chain: %v", err)
+	}
+	t.Cleanup(func() { chain.Close() })
+
+	wlt, err := token.NewWhitelistableToken(chain, chain.Key, chain.ContractAddress)
+	if err != nil {
+		t.Fatalf("can't setup token context: %v", err)
+	}
+	t.Cleanup(wlt.Close)
+
+	return &Env{chain, wlt}
+}
+
+// NewAddress returns hex of a fresh random address
+func NewAddress(t testing.TB) string {
+	t.Helper()
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("can't generate key: %v", err)
+	}
+
+	return crypto.PubkeyToAddress(key.PublicKey).Hex()
+}
+
+// Whitelist grants WhitelistedRole to the address through the token context
+func (e *Env) Whitelist(t testing.TB, address string) {
+	t.Helper()
+
+	if _, err := e.Token.WhitelistAddress(&token.WhitelistInput{Address: address}); err != nil {
+		t.Fatalf("can't whitelist %s: %v", address, err)
+	}
+}
+
+// HasRole reads role membership of the address
+func (e *Env) HasRole(t testing.TB, role [32]byte, address string) bool {
+	t.Helper()
+
+	has, err := e.Chain.Token.HasRole(&bind.CallOpts{}, role, common.HexToAddress(address))
+	if err != nil {
+		t.Fatalf("can't read role of %s: %v", address, err)
+	}
+
+	return has
+}
+
+// BalanceOf reads token balance of the address
+func (e *Env) BalanceOf(t testing.TB, address string) *big.Int {
+	t.Helper()
+
+	balance, err := e.Chain.Token.BalanceOf(&bind.CallOpts{}, common.HexToAddress(address))
+	if err != nil {
+		t.Fatalf("can't read balance of %s: %v", address, err)
+	}
+
+	return balance
+}